@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// newConfigCmd's subcommands must work even when the config is currently
+// invalid, so they skip the root's PersistentPreRunE (which would otherwise
+// fail building the application before RunE ever gets a chance to report
+// the problem).
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "config",
+		Short:             "Inspect the resolved configuration",
+		PersistentPreRunE: noOpPersistentPreRun,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "Resolve and validate the config (file + env + flags), reporting any errors",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, _, err := ResolveConfig(rootFlags.configPath, rootFlags.storeDriver); err != nil {
+				return err
+			}
+			fmt.Println("config OK")
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "print",
+		Short: "Print the resolved configuration and each field's source (file/env/flag/default)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printResolvedConfig(rootFlags.configPath, rootFlags.storeDriver)
+			return nil
+		},
+	})
+
+	return cmd
+}
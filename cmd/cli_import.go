@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+func newImportCmd() *cobra.Command {
+	var compareTo int64
+	var reportDir string
+
+	cmd := &cobra.Command{
+		Use:   "import <zip>",
+		Short: "Unzip and import an Instagram/Threads data export into the store",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zipPath := args[0]
+
+			dest, err := app.unzipData(zipPath)
+			if err != nil {
+				return fmt.Errorf("unzip data: %w", err)
+			}
+			app.info("parser", "Data unzipped", slog.String("destDir", dest))
+
+			if err := app.parseToDB(dest, compareTo, reportDir); err != nil {
+				return fmt.Errorf("parse and import data: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Int64Var(&compareTo, "compare-to", 0, "Snapshot id to diff the new import against")
+	cmd.Flags().StringVar(&reportDir, "report-dir", "", "Directory to write an HTML/CSV report to after import")
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
+)
+
+func newProtectCmd() *cobra.Command {
+	var reason string
+	var accessFlag string
+
+	cmd := &cobra.Command{
+		Use:   "protect <username>",
+		Short: "Add a username to the allow-list so it's never surfaced as an unfollow candidate",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			access := storage.Access(accessFlag)
+			switch access {
+			case storage.AccessPublic, storage.AccessPrivate:
+			default:
+				return fmt.Errorf("--access must be %q or %q, got %q", storage.AccessPublic, storage.AccessPrivate, accessFlag)
+			}
+			return app.protectAccount(args[0], reason, access)
+		},
+	}
+
+	cmd.Flags().StringVar(&reason, "reason", "", "Reason recorded alongside the protected account")
+	cmd.Flags().StringVar(&accessFlag, "access", string(storage.AccessPublic), "Whether the handle stays visible (PUBLIC) or is redacted (PRIVATE) in exported reports")
+
+	return cmd
+}
+
+func newUnprotectCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unprotect <username>",
+		Short: "Remove a username from the allow-list",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.unprotectAccount(args[0])
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newStatsCmd() *cobra.Command {
+	var churnSince time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Print a summary of the follow graph (mutuals, fans not followed back, unfollow candidates)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var since time.Time
+			if churnSince > 0 {
+				since = time.Now().Add(-churnSince)
+			}
+			return app.showStats(since)
+		},
+	}
+
+	cmd.Flags().DurationVar(&churnSince, "churn-since", 0, "Include follower churn since this long ago in the output, e.g. 168h")
+
+	return cmd
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newUnfollowCmd() *cobra.Command {
+	var dryRun bool
+	var limit int
+	var whitelistPath string
+	var nonFollowersOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "unfollow",
+		Short: "Unfollow accounts that don't follow back, skipping protected and whitelisted accounts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := unfollowOptions{DryRun: dryRun, Limit: limit, NonFollowersOnly: nonFollowersOnly}
+			if whitelistPath != "" {
+				whitelist, err := loadWhitelist(whitelistPath)
+				if err != nil {
+					return fmt.Errorf("load whitelist: %w", err)
+				}
+				opts.Whitelist = whitelist
+			}
+			return app.runUnfollow(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Log what would be unfollowed without taking action or recording it")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Stop after this many successful unfollows across all accounts (0 = unlimited)")
+	cmd.Flags().StringVar(&whitelistPath, "whitelist", "", "File of usernames (one per line) to skip for this run, in addition to protected accounts")
+	cmd.Flags().BoolVar(&nonFollowersOnly, "non-followers-only", false, "Re-verify each batch against the account's live follower graph before acting, catching anyone who's followed back since the last import")
+
+	return cmd
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Validate fills in sane defaults for fields the user left unset and
+// rejects obviously-wrong values, so a bad or partial config file doesn't
+// silently misbehave.
+func (c *config) Validate() error {
+	if c.App.UnfollowDelaySeconds == 0 {
+		c.App.UnfollowDelaySeconds = 5
+	}
+	if c.App.MaxRetries == 0 {
+		c.App.MaxRetries = 3
+	}
+	if c.App.BackoffMultiplier == 0 {
+		c.App.BackoffMultiplier = 2
+	}
+	if c.App.SafetyBufferPercent == 0 {
+		c.App.SafetyBufferPercent = 10
+	}
+	if c.App.CooldownAfterBlockMinutes == 0 {
+		c.App.CooldownAfterBlockMinutes = 30
+	}
+	if c.Instagram.AutomationLimits.Actions.Hourly == 0 {
+		c.Instagram.AutomationLimits.Actions.Hourly = 60
+	}
+	if c.Instagram.AutomationLimits.Actions.Daily == 0 {
+		c.Instagram.AutomationLimits.Actions.Daily = 150
+	}
+	if c.Logging.Format == "" {
+		c.Logging.Format = "text"
+	}
+	if c.Logging.Level == "" {
+		c.Logging.Level = "info"
+	}
+
+	if err := validateRateLimit("instagram.automation_limits.actions", c.Instagram.AutomationLimits.Actions.Hourly, c.Instagram.AutomationLimits.Actions.Daily); err != nil {
+		return err
+	}
+	if err := validateOperationLimit("instagram.operations.follow", c.Instagram.Operations.Follow); err != nil {
+		return err
+	}
+	if err := validateOperationLimit("instagram.operations.unfollow", c.Instagram.Operations.Unfollow); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateRateLimit(path string, hourly, daily int) error {
+	if hourly < 0 {
+		return fmt.Errorf("%s.hourly must not be negative, got %d", path, hourly)
+	}
+	if daily < 0 {
+		return fmt.Errorf("%s.daily must not be negative, got %d", path, daily)
+	}
+	if hourly > 0 && daily > 0 && daily < hourly {
+		return fmt.Errorf("%s.daily (%d) must not be less than %s.hourly (%d)", path, daily, path, hourly)
+	}
+	return nil
+}
+
+func validateOperationLimit(path string, op operationLimit) error {
+	if op.MaxPerHour < 0 {
+		return fmt.Errorf("%s.max_per_hour must not be negative, got %d", path, op.MaxPerHour)
+	}
+	if op.MaxPerDay < 0 {
+		return fmt.Errorf("%s.max_per_day must not be negative, got %d", path, op.MaxPerDay)
+	}
+	if op.MaxPerHour > 0 && op.MaxPerDay > 0 && op.MaxPerDay < op.MaxPerHour {
+		return fmt.Errorf("%s.max_per_day (%d) must not be less than %s.max_per_hour (%d)", path, op.MaxPerDay, path, op.MaxPerHour)
+	}
+	return nil
+}
+
+// ConfigManager holds the active config and keeps it current: it reloads
+// from configPath on SIGHUP and whenever the file changes on disk,
+// validating each candidate before swapping it in so a bad edit never
+// takes effect.
+type ConfigManager struct {
+	configPath  string
+	storeDriver string
+	logger      *slog.Logger
+	current     atomic.Pointer[config]
+}
+
+// NewConfigManager resolves the config from configPath and storeDriver (see
+// ResolveConfig), returning a ConfigManager primed with the result.
+func NewConfigManager(configPath, storeDriver string, logger *slog.Logger) (*ConfigManager, error) {
+	cfg, _, err := ResolveConfig(configPath, storeDriver)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ConfigManager{configPath: configPath, storeDriver: storeDriver, logger: logger}
+	m.current.Store(cfg)
+	return m, nil
+}
+
+// Current returns the active, validated config. The unfollow worker (and
+// everything else) should call this at each action boundary rather than
+// caching the result, so a hot-reload takes effect promptly.
+func (m *ConfigManager) Current() config {
+	return *m.current.Load()
+}
+
+// SetLogger updates the logger used for reload diagnostics, once the real
+// logger (built from the initially loaded config) is available.
+func (m *ConfigManager) SetLogger(logger *slog.Logger) {
+	m.logger = logger
+}
+
+// Reload re-resolves the config from configPath/storeDriver (file + env +
+// flags, see ResolveConfig), swapping it in only on success and logging a
+// structured diff of what changed. An invalid candidate is logged and
+// discarded, leaving the previously active config in place.
+func (m *ConfigManager) Reload() error {
+	next, _, err := ResolveConfig(m.configPath, m.storeDriver)
+	if err != nil {
+		m.logger.Error("Config reload failed", slog.String("path", m.configPath), slog.Any("error", err))
+		return err
+	}
+
+	prev := m.current.Swap(next)
+	diff := diffConfig(*prev, *next)
+	if len(diff) == 0 {
+		m.logger.Debug("Config reloaded, no changes", slog.String("path", m.configPath))
+		return nil
+	}
+	m.logger.Info("Config reloaded", slog.String("path", m.configPath), slog.Any("changed", diff))
+	return nil
+}
+
+// Watch reloads the config on SIGHUP and whenever the file changes on
+// disk, until ctx is canceled. It runs in the caller's goroutine; run it
+// with `go manager.Watch(ctx)`.
+func (m *ConfigManager) Watch(ctx context.Context) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		m.logger.Warn("Config file watch disabled: could not start fsnotify", slog.Any("error", err))
+		watcher = nil
+	} else {
+		defer watcher.Close()
+		// Watch the containing directory rather than the file itself:
+		// editors and config-management tools commonly replace the file
+		// (rename over it) rather than writing in place, which a
+		// file-level watch would miss.
+		if err := watcher.Add(filepath.Dir(m.configPath)); err != nil {
+			m.logger.Warn("Config file watch disabled: could not watch directory", slog.Any("error", err))
+			watcher.Close()
+			watcher = nil
+		}
+	}
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if watcher != nil {
+		events = watcher.Events
+		errs = watcher.Errors
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-sighup:
+			m.logger.Info("Reloading config on signal", slog.String("signal", sig.String()))
+			m.Reload()
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(m.configPath) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			m.Reload()
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			m.logger.Warn("Config watcher error", slog.Any("error", err))
+		}
+	}
+}
+
+// diffConfig returns human-readable "path: old -> new" entries for every
+// scalar or slice field that differs between a and b, comparing
+// recursively through nested structs.
+func diffConfig(a, b config) []string {
+	var diffs []string
+	diffStructs("", reflect.ValueOf(a), reflect.ValueOf(b), &diffs)
+	return diffs
+}
+
+func diffStructs(prefix string, a, b reflect.Value, diffs *[]string) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := field.Name
+		if prefix != "" {
+			path = prefix + "." + field.Name
+		}
+
+		fa, fb := a.Field(i), b.Field(i)
+		switch fa.Kind() {
+		case reflect.Struct:
+			diffStructs(path, fa, fb, diffs)
+		case reflect.Slice:
+			if fmt.Sprint(fa.Interface()) != fmt.Sprint(fb.Interface()) {
+				*diffs = append(*diffs, fmt.Sprintf("%s: %v -> %v", path, fa.Interface(), fb.Interface()))
+			}
+		default:
+			if fa.Interface() != fb.Interface() {
+				*diffs = append(*diffs, fmt.Sprintf("%s: %v -> %v", path, fa.Interface(), fb.Interface()))
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfigFile reads path and unmarshals it into a config, choosing the
+// format by file extension: ".toml" decodes as TOML (BurntSushi/toml),
+// anything else (".yaml", ".yml", or no extension) as YAML.
+func loadConfigFile(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config
+	if strings.HasSuffix(path, ".toml") {
+		if err := toml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parse toml: %w", err)
+		}
+		return &cfg, nil
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse yaml: %w", err)
+	}
+	return &cfg, nil
+}
+
+// envOverrides applies the fixed set of environment-variable overrides
+// listed below, recording each one's source in provenance so `config
+// print` can show where every value came from.
+func envOverrides(cfg *config, provenance map[string]string) error {
+	overrides := []struct {
+		env  string
+		path string
+		set  func(string) error
+	}{
+		{"INSTAGRAM_ACTIONS_HOURLY", "Instagram.AutomationLimits.Actions.Hourly", intSetter(&cfg.Instagram.AutomationLimits.Actions.Hourly)},
+		{"INSTAGRAM_ACTIONS_DAILY", "Instagram.AutomationLimits.Actions.Daily", intSetter(&cfg.Instagram.AutomationLimits.Actions.Daily)},
+		{"APP_UNFOLLOW_DELAY_SECONDS", "App.UnfollowDelaySeconds", intSetter(&cfg.App.UnfollowDelaySeconds)},
+		{"APP_MAX_RETRIES", "App.MaxRetries", intSetter(&cfg.App.MaxRetries)},
+		{"APP_STORE", "App.Store", stringSetter(&cfg.App.Store)},
+		{"APP_EXTRACTED_PATH", "App.ExtractedPath", stringSetter(&cfg.App.ExtractedPath)},
+		{"LOGGING_FORMAT", "Logging.Format", stringSetter(&cfg.Logging.Format)},
+		{"LOGGING_LEVEL", "Logging.Level", stringSetter(&cfg.Logging.Level)},
+	}
+
+	for _, o := range overrides {
+		val, ok := os.LookupEnv(o.env)
+		if !ok || val == "" {
+			continue
+		}
+		if err := o.set(val); err != nil {
+			return fmt.Errorf("env %s: %w", o.env, err)
+		}
+		provenance[o.path] = "env"
+	}
+
+	return nil
+}
+
+func intSetter(dst *int) func(string) error {
+	return func(val string) error {
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			return err
+		}
+		*dst = n
+		return nil
+	}
+}
+
+func stringSetter(dst *string) func(string) error {
+	return func(val string) error {
+		*dst = val
+		return nil
+	}
+}
+
+// ResolveConfig builds the effective config by layering, lowest to highest
+// precedence: built-in defaults (filled in by Validate), configPath's file,
+// environment variables (see envOverrides), then storeDriver (the --store
+// flag, if set). It returns the merged config alongside a provenance map
+// (dotted field path -> "file", "env", "flag", or "default") for `config
+// print`.
+func ResolveConfig(configPath, storeDriver string) (*config, map[string]string, error) {
+	provenance := map[string]string{}
+	flattenProvenance("", reflect.ValueOf(config{}), provenance)
+
+	cfg, err := loadConfigFile(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config file %q: %w", configPath, err)
+	}
+	markNonZero("", reflect.ValueOf(*cfg), provenance, "file")
+
+	if err := envOverrides(cfg, provenance); err != nil {
+		return nil, nil, err
+	}
+
+	if storeDriver != "" {
+		cfg.App.Store = storeDriver
+		provenance["App.Store"] = "flag"
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("validate config: %w", err)
+	}
+
+	return cfg, provenance, nil
+}
+
+// printResolvedConfig implements `config print`: it resolves the config the
+// same way real application startup would, then prints every leaf field's
+// value and where it came from, without starting anything.
+func printResolvedConfig(configPath, storeDriver string) {
+	cfg, provenance, err := ResolveConfig(configPath, storeDriver)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "resolve config: %v\n", err)
+		os.Exit(1)
+	}
+
+	values := map[string]string{}
+	flattenValues("", reflect.ValueOf(*cfg), values)
+
+	paths := make([]string, 0, len(provenance))
+	for path := range provenance {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		fmt.Printf("%-55s %-20v (%s)\n", path, values[path], provenance[path])
+	}
+}
+
+func flattenValues(prefix string, v reflect.Value, out map[string]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := field.Name
+		if prefix != "" {
+			path = prefix + "." + field.Name
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			flattenValues(path, fv, out)
+			continue
+		}
+		out[path] = fmt.Sprint(fv.Interface())
+	}
+}
+
+func flattenProvenance(prefix string, v reflect.Value, out map[string]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := field.Name
+		if prefix != "" {
+			path = prefix + "." + field.Name
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			flattenProvenance(path, fv, out)
+			continue
+		}
+		out[path] = "default"
+	}
+}
+
+func markNonZero(prefix string, v reflect.Value, out map[string]string, source string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := field.Name
+		if prefix != "" {
+			path = prefix + "." + field.Name
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			markNonZero(path, fv, out, source)
+			continue
+		}
+		if !fv.IsZero() {
+			out[path] = source
+		}
+	}
+}
@@ -8,26 +8,26 @@ import (
 )
 
 func (app *application) listCandidates() error {
-	// Open database to get candidates
-	db, err := storage.Open("instagram.db")
+	// Open store to get candidates
+	store, err := storage.OpenStore(app.storeDriver())
 	if err != nil {
 		return err
 	}
-	defer db.Close()
+	defer store.Close()
 
 	// Get stats
-	unfollowedCount, err := storage.UnfollowedCount(db)
+	unfollowedCount, err := store.UnfollowedCount()
 	if err != nil {
-		app.logger.Warn("Could not get unfollowed count", slog.Any("error", err))
+		app.warn("unfollow", "Could not get unfollowed count", slog.Any("error", err))
 	}
 
 	// Get candidates
-	candidates, err := storage.UnfollowCandidates(db)
+	candidates, err := store.UnfollowCandidates()
 	if err != nil {
 		return err
 	}
 
-	app.logger.Info("Unfollow statistics",
+	app.info("unfollow", "Unfollow statistics",
 		slog.Int("remaining", len(candidates)),
 		slog.Int("already_unfollowed", unfollowedCount),
 	)
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+type loggingConfig struct {
+	// Format is "text" or "json". Defaults to "text".
+	Format string `yaml:"format" toml:"format"`
+	// Level is "debug", "info", "warn", or "error". Defaults to "info".
+	Level string        `yaml:"level" toml:"level"`
+	File  logFileConfig `yaml:"file" toml:"file"`
+}
+
+// logFileConfig, if Path is set, additionally writes logs to a rotated file
+// on top of stdout.
+type logFileConfig struct {
+	Path       string `yaml:"path" toml:"path"`
+	MaxSizeMB  int    `yaml:"max_size_mb" toml:"max_size_mb"`
+	MaxBackups int    `yaml:"max_backups" toml:"max_backups"`
+	MaxAgeDays int    `yaml:"max_age_days" toml:"max_age_days"`
+}
+
+// buildLogger constructs the application's root *slog.Logger from cfg. The
+// returned io.Closer is non-nil only when file output is enabled, and
+// should be closed on shutdown to flush the rotated log file.
+func buildLogger(cfg loggingConfig) (*slog.Logger, io.Closer) {
+	var level slog.Level
+	switch cfg.Level {
+	case "debug":
+		level = slog.LevelDebug
+	case "warn":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	default:
+		level = slog.LevelInfo
+	}
+
+	var w io.Writer = os.Stdout
+	var closer io.Closer
+	if cfg.File.Path != "" {
+		rotator := &lumberjack.Logger{
+			Filename:   cfg.File.Path,
+			MaxSize:    cfg.File.MaxSizeMB,
+			MaxBackups: cfg.File.MaxBackups,
+			MaxAge:     cfg.File.MaxAgeDays,
+		}
+		w = io.MultiWriter(os.Stdout, rotator)
+		closer = rotator
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if cfg.Format == "json" {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+
+	return slog.New(handler), closer
+}
+
+// newRunID generates a short identifier attached to every log line this
+// process emits, so entries from a given run can be grepped out of a
+// shared log file.
+func newRunID() string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// log is the shared implementation behind app.debug/info/warn/error: every
+// entry is tagged with its subsystem ("unfollow", "parser", "config",
+// "ratelimit", ...), this run's id, and the account currently being
+// automated, if any.
+func (app *application) log(level slog.Level, subsystem, msg string, args ...any) {
+	fields := make([]any, 0, len(args)+3)
+	fields = append(fields, slog.String("subsystem", subsystem))
+	if app.runID != "" {
+		fields = append(fields, slog.String("run_id", app.runID))
+	}
+	if app.currentAccount != "" {
+		fields = append(fields, slog.String("account", app.currentAccount))
+	}
+	fields = append(fields, args...)
+	app.logger.Log(context.Background(), level, msg, fields...)
+}
+
+func (app *application) debug(subsystem, msg string, args ...any) {
+	app.log(slog.LevelDebug, subsystem, msg, args...)
+}
+
+func (app *application) info(subsystem, msg string, args ...any) {
+	app.log(slog.LevelInfo, subsystem, msg, args...)
+}
+
+func (app *application) warn(subsystem, msg string, args ...any) {
+	app.log(slog.LevelWarn, subsystem, msg, args...)
+}
+
+func (app *application) error(subsystem, msg string, args ...any) {
+	app.log(slog.LevelError, subsystem, msg, args...)
+}
+
+// subsystemLogger returns a plain *slog.Logger pre-tagged with subsystem and
+// this run's id, for handing to packages (pkg/browser, pkg/power,
+// pkg/ratelimit, pkg/drivers/...) that take a *slog.Logger via constructor
+// injection rather than calling back into app.info/warn/error.
+func (app *application) subsystemLogger(subsystem string) *slog.Logger {
+	attrs := []any{slog.String("subsystem", subsystem)}
+	if app.runID != "" {
+		attrs = append(attrs, slog.String("run_id", app.runID))
+	}
+	return app.logger.With(attrs...)
+}
@@ -1,133 +1,193 @@
 package main
 
 import (
-	"flag"
+	"io"
 	"log/slog"
 	"os"
+	"time"
 
-	"gopkg.in/yaml.v3"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/ratelimit"
 )
 
 type config struct {
-	Instagram instagramConfig `yaml:"instagram"`
-	App       appConfig       `yaml:"app"`
+	Instagram instagramConfig `yaml:"instagram" toml:"instagram"`
+	App       appConfig       `yaml:"app" toml:"app"`
+	// Accounts lists which driver (see pkg/drivers) each automated account
+	// uses. Omitting it preserves the historical behavior of running the
+	// single "instagram" driver against the configured store.
+	Accounts []accountConfig `yaml:"accounts" toml:"accounts"`
+	Logging  loggingConfig   `yaml:"logging" toml:"logging"`
+}
+
+type accountConfig struct {
+	Username string `yaml:"username" toml:"username"`
+	Driver   string `yaml:"driver" toml:"driver"`
 }
 
 type instagramConfig struct {
-	AutomationLimits automationLimits `yaml:"automation_limits"`
-	Operations       operations       `yaml:"operations"`
+	AutomationLimits automationLimits `yaml:"automation_limits" toml:"automation_limits"`
+	Operations       operations       `yaml:"operations" toml:"operations"`
 }
 
 type automationLimits struct {
-	Actions rateLimit `yaml:"actions"`
+	Actions rateLimit `yaml:"actions" toml:"actions"`
 }
 
 type rateLimit struct {
-	Hourly            int `yaml:"hourly"`
-	Daily             int `yaml:"daily"`
-	TimeWindowSeconds int `yaml:"time_window_seconds"`
+	Hourly            int `yaml:"hourly" toml:"hourly"`
+	Daily             int `yaml:"daily" toml:"daily"`
+	TimeWindowSeconds int `yaml:"time_window_seconds" toml:"time_window_seconds"`
 }
 
 type operations struct {
-	Follow   operationLimit `yaml:"follow"`
-	Unfollow operationLimit `yaml:"unfollow"`
+	Follow   operationLimit `yaml:"follow" toml:"follow"`
+	Unfollow operationLimit `yaml:"unfollow" toml:"unfollow"`
 }
 
 type operationLimit struct {
-	MaxPerHour int `yaml:"max_per_hour"`
-	MaxPerDay  int `yaml:"max_per_day"`
+	MaxPerHour int `yaml:"max_per_hour" toml:"max_per_hour"`
+	MaxPerDay  int `yaml:"max_per_day" toml:"max_per_day"`
 }
 
 type appConfig struct {
-	Version              string        `yaml:"version"`
-	ExtractedPath        string        `yaml:"extracted_path"`
-	UnfollowDelaySeconds int           `yaml:"unfollow_delay_seconds"`
-	MaxRetries           int           `yaml:"max_retries"`
-	BackoffMultiplier    int           `yaml:"backoff_multiplier"`
-	SafetyBufferPercent  int           `yaml:"safety_buffer_percent"`
-	Session              sessionConfig `yaml:"session"`
+	Version              string        `yaml:"version" toml:"version"`
+	ExtractedPath        string        `yaml:"extracted_path" toml:"extracted_path"`
+	UnfollowDelaySeconds int           `yaml:"unfollow_delay_seconds" toml:"unfollow_delay_seconds"`
+	MaxRetries           int           `yaml:"max_retries" toml:"max_retries"`
+	BackoffMultiplier    int           `yaml:"backoff_multiplier" toml:"backoff_multiplier"`
+	SafetyBufferPercent  int           `yaml:"safety_buffer_percent" toml:"safety_buffer_percent"`
+	Store                string        `yaml:"store" toml:"store"`
+	Session              sessionConfig `yaml:"session" toml:"session"`
+
+	// UnfollowCooldownMinutes is how long the unfollow ledger skips a
+	// recently-attempted (but not terminally resolved) account, even if it
+	// re-appears in the candidate list. Zero disables the cooldown.
+	UnfollowCooldownMinutes int `yaml:"unfollow_cooldown_minutes" toml:"unfollow_cooldown_minutes"`
+	// AllowListPath, if set, is a do-not-touch file of usernames (one per
+	// line) the unfollow ledger will always skip, in addition to whatever
+	// it's already resolved.
+	AllowListPath string `yaml:"allow_list_path" toml:"allow_list_path"`
+	// CooldownAfterBlockMinutes is how long the adaptive rate limiter
+	// reports waiting before resuming after Instagram shows an Action
+	// Blocked dialog, on top of doubling the persisted base delay.
+	CooldownAfterBlockMinutes int `yaml:"cooldown_after_block_minutes" toml:"cooldown_after_block_minutes"`
 }
 
 type sessionConfig struct {
-	MaxActionsPerSession       int  `yaml:"max_actions_per_session"`
-	SessionRestartDelayMinutes int  `yaml:"session_restart_delay_minutes"`
-	RandomizeHeaders           bool `yaml:"randomize_headers"`
+	MaxActionsPerSession       int  `yaml:"max_actions_per_session" toml:"max_actions_per_session"`
+	SessionRestartDelayMinutes int  `yaml:"session_restart_delay_minutes" toml:"session_restart_delay_minutes"`
+	RandomizeHeaders           bool `yaml:"randomize_headers" toml:"randomize_headers"`
 }
 
 type application struct {
-	config config
-	logger *slog.Logger
+	cfgManager      *ConfigManager
+	logger          *slog.Logger
+	logCloser       io.Closer
+	storeDriverFlag string
+
+	// runID tags every log line this process emits (see cmd/logging.go),
+	// and currentAccount additionally tags lines emitted while a specific
+	// account (see accountConfig) is being automated.
+	runID          string
+	currentAccount string
 }
 
-func getFlags() (string, string, bool) {
-	var configPath string
-	var dataPath string
-	var runUnfollow bool
-	flag.StringVar(&configPath, "config", "config.yaml", "Path to configuration file")
-	flag.StringVar(&dataPath, "data", "", "Path to Instagram export zip file")
-	flag.BoolVar(&runUnfollow, "unfollow", false, "Run the unfollow process")
-	flag.Parse()
-
-	if configPath != "" {
-		return configPath, dataPath, runUnfollow
-	}
-
-	if envPath := os.Getenv("CONFIG_PATH"); envPath != "" {
-		return envPath, dataPath, runUnfollow
-	}
-
-	return "config.yaml", dataPath, runUnfollow
+// cfg returns the currently active config. Call it at each use rather than
+// caching the result, so a config hot-reload (see cmd/config_manager.go)
+// takes effect at the next read instead of only at startup.
+func (app *application) cfg() config {
+	return app.cfgManager.Current()
 }
 
-func loadConfig(path string) (*config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+// storeDriver returns the storage driver URL to use, preferring an explicit
+// --store flag over the config file, and falling back to a local SQLite
+// database for backward compatibility.
+func (app *application) storeDriver() string {
+	if app.storeDriverFlag != "" {
+		return app.storeDriverFlag
+	}
+	if app.cfg().App.Store != "" {
+		return app.cfg().App.Store
 	}
+	return "sqlite://instagram.db"
+}
 
-	var cfg config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, err
+// actionLimits builds the per-action ratelimit.ActionLimit set from
+// Instagram.AutomationLimits: Operations.<action> supplies hourly/daily
+// caps (falling back to the generic Actions cap when an action has none
+// of its own), App.UnfollowDelaySeconds seeds the minimum inter-action
+// delay with jitter, Actions.TimeWindowSeconds overrides the hourly cap's
+// window (e.g. pacing 20 actions per 1800s instead of per a flat hour),
+// and App.SafetyBufferPercent shaves that much off every cap so the
+// ledger (pkg/ratelimit, persisted via the store) never actually lets
+// automation run right up against Instagram's own soft limits.
+func (app *application) actionLimits() map[string]ratelimit.ActionLimit {
+	cfg := app.cfg()
+	def := cfg.Instagram.AutomationLimits.Actions
+	minDelay := time.Duration(cfg.App.UnfollowDelaySeconds) * time.Second
+
+	hourlyWindow := time.Hour
+	if def.TimeWindowSeconds > 0 {
+		hourlyWindow = time.Duration(def.TimeWindowSeconds) * time.Second
 	}
 
-	return &cfg, nil
-}
+	limitFor := func(op operationLimit) ratelimit.ActionLimit {
+		hourly := op.MaxPerHour
+		if hourly == 0 {
+			hourly = def.Hourly
+		}
+		daily := op.MaxPerDay
+		if daily == 0 {
+			daily = def.Daily
+		}
+		hourly = withSafetyBuffer(hourly, cfg.App.SafetyBufferPercent)
+		daily = withSafetyBuffer(daily, cfg.App.SafetyBufferPercent)
 
-func main() {
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+		var windows []ratelimit.Window
+		if hourly > 0 {
+			windows = append(windows, ratelimit.Window{Period: hourlyWindow, Max: hourly})
+		}
+		if daily > 0 {
+			windows = append(windows, ratelimit.Window{Period: 24 * time.Hour, Max: daily})
+		}
 
-	configPath, dataPath, doUnfollow := getFlags()
-	cfg, err := loadConfig(configPath)
-	if err != nil {
-		logger.Error("Failed to load config", slog.String("path", configPath), slog.Any("error", err))
-		os.Exit(1)
+		return ratelimit.ActionLimit{
+			Windows:  windows,
+			MinDelay: minDelay,
+			Jitter:   minDelay / 3,
+		}
 	}
 
-	app := &application{
-		config: *cfg,
-		logger: logger,
+	return map[string]ratelimit.ActionLimit{
+		"unfollow": limitFor(cfg.Instagram.Operations.Unfollow),
+		"follow":   limitFor(cfg.Instagram.Operations.Follow),
 	}
+}
 
-	app.logger.Info("Application started", slog.String("configPath", configPath))
+// withSafetyBuffer reduces max by percent, rounding down but never below 1
+// for a positive max - a configured cap should always leave some headroom
+// below the platform's actual soft limit, not sit exactly on it.
+func withSafetyBuffer(max, percent int) int {
+	if max <= 0 || percent <= 0 {
+		return max
+	}
+	if percent >= 100 {
+		return 1
+	}
+	buffered := max * (100 - percent) / 100
+	if buffered < 1 {
+		buffered = 1
+	}
+	return buffered
+}
 
-	if dataPath != "" {
-		dest, err := app.unzipData(dataPath)
-		if err != nil {
-			app.logger.Error("Failed to unzip data", slog.String("dataPath", dataPath), slog.Any("error", err))
-			os.Exit(1)
-		}
-		app.logger.Info("Data unzipped", slog.String("destDir", dest))
+func main() {
+	err := newRootCmd().Execute()
 
-		if err := app.parseToDB(dest); err != nil {
-			app.logger.Error("Failed to parse and import data", slog.Any("error", err))
-			os.Exit(1)
-		}
+	if app != nil && app.logCloser != nil {
+		app.logCloser.Close()
 	}
-
-	if doUnfollow {
-		if err := app.runUnfollow(); err != nil {
-			app.logger.Error("Unfollow process failed", slog.Any("error", err))
-			os.Exit(1)
-		}
+	if err != nil {
+		os.Exit(1)
 	}
 }
@@ -6,57 +6,120 @@ import (
 	"path/filepath"
 
 	"github.com/worlddrknss/instagram-unfollow-v2/pkg/extraction"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/report"
 	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
 )
 
-func (app *application) parseToDB(extractedDir string) error {
+func (app *application) parseToDB(extractedDir string, compareTo int64, reportDir string) error {
 	connectionsDir := filepath.Join(extractedDir, "connections", "followers_and_following")
 
-	// Open database
-	dbPath := "instagram.db"
-	db, err := storage.Open(dbPath)
+	// Open store
+	store, err := storage.OpenStore(app.storeDriver())
 	if err != nil {
-		return fmt.Errorf("open db: %w", err)
+		return fmt.Errorf("open store: %w", err)
 	}
-	defer db.Close()
+	defer store.Close()
 
-	// Parse and insert following
-	followingPath := filepath.Join(connectionsDir, "following.json")
-	following, err := extraction.ParseFollowing(followingPath)
+	snapshotID, err := store.NewSnapshot(extractedDir)
 	if err != nil {
-		return fmt.Errorf("parse following: %w", err)
+		return fmt.Errorf("create snapshot: %w", err)
 	}
-	if err := storage.UpsertFollowing(db, following); err != nil {
+
+	// Parse and insert following/followers, auto-detecting whether this
+	// export is the JSON or HTML variant.
+	following, allFollowers, err := extraction.Parse(connectionsDir)
+	if err != nil {
+		return fmt.Errorf("parse following/followers: %w", err)
+	}
+	if err := store.UpsertFollowing(following); err != nil {
 		return fmt.Errorf("upsert following: %w", err)
 	}
-	app.logger.Info("Imported following", slog.Int("count", len(following)))
+	if err := store.RecordSnapshotRelationships(snapshotID, "following", following); err != nil {
+		return fmt.Errorf("record following snapshot: %w", err)
+	}
+	app.info("parser", "Imported following", slog.Int("count", len(following)))
 
-	// Find and parse all followers files
-	followerFiles, err := extraction.FindFollowerFiles(connectionsDir)
-	if err != nil {
-		return fmt.Errorf("find follower files: %w", err)
+	if err := store.UpsertFollowers(allFollowers); err != nil {
+		return fmt.Errorf("upsert followers: %w", err)
 	}
+	if err := store.RecordSnapshotRelationships(snapshotID, "followers", allFollowers); err != nil {
+		return fmt.Errorf("record followers snapshot: %w", err)
+	}
+	app.info("parser", "Imported followers", slog.Int("count", len(allFollowers)))
 
-	var allFollowers []storage.Relationship
-	for _, file := range followerFiles {
-		followers, err := extraction.ParseFollowers(file)
+	// Parse and insert every other known connection kind (close friends,
+	// restricted profiles, pending requests, etc.)
+	for _, kind := range extraction.ConnectionKinds() {
+		path, err := extraction.FindConnectionFile(connectionsDir, kind)
 		if err != nil {
-			return fmt.Errorf("parse %s: %w", file, err)
+			return fmt.Errorf("find %s: %w", kind, err)
+		}
+		if path == "" {
+			continue
 		}
-		allFollowers = append(allFollowers, followers...)
-	}
 
-	if err := storage.UpsertFollowers(db, allFollowers); err != nil {
-		return fmt.Errorf("upsert followers: %w", err)
+		rels, err := extraction.ParseConnectionFile(path, kind)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", kind, err)
+		}
+		if err := store.UpsertRelationships(string(kind), rels); err != nil {
+			return fmt.Errorf("upsert %s: %w", kind, err)
+		}
+		app.info("parser", "Imported connection list", slog.String("kind", string(kind)), slog.Int("count", len(rels)))
 	}
-	app.logger.Info("Imported followers", slog.Int("count", len(allFollowers)), slog.Int("files", len(followerFiles)))
 
 	// Get unfollow candidates
-	candidates, err := storage.UnfollowCandidates(db)
+	candidates, err := store.UnfollowCandidates()
 	if err != nil {
 		return fmt.Errorf("get candidates: %w", err)
 	}
-	app.logger.Info("Found unfollow candidates", slog.Int("count", len(candidates)))
+	protected, err := store.ListProtected()
+	if err != nil {
+		return fmt.Errorf("list protected accounts: %w", err)
+	}
+	app.info("parser", "Found unfollow candidates",
+		slog.Int("count", len(candidates)),
+		slog.Int("protected_filtered", len(protected)),
+	)
+
+	fans, err := store.FanCandidates()
+	if err != nil {
+		return fmt.Errorf("get fan candidates: %w", err)
+	}
+	mutuals, err := store.Mutuals()
+	if err != nil {
+		return fmt.Errorf("get mutuals: %w", err)
+	}
+	app.info("parser", "Import summary",
+		slog.Int("following", len(following)),
+		slog.Int("followers", len(allFollowers)),
+		slog.Int("unfollow_candidates", len(candidates)),
+		slog.Int("fans", len(fans)),
+		slog.Int("mutuals", len(mutuals)),
+	)
+
+	if reportDir != "" {
+		if err := report.Generate(store, reportDir); err != nil {
+			return fmt.Errorf("generate report: %w", err)
+		}
+		app.info("parser", "Wrote report", slog.String("dir", reportDir))
+	}
+
+	if compareTo > 0 {
+		newUnfollowers, err := store.NewUnfollowers(compareTo)
+		if err != nil {
+			return fmt.Errorf("compare unfollowers: %w", err)
+		}
+		newFollowers, err := store.NewFollowers(compareTo)
+		if err != nil {
+			return fmt.Errorf("compare followers: %w", err)
+		}
+		app.info("parser", "Compared against snapshot",
+			slog.Int64("since_snapshot", compareTo),
+			slog.Int("new_unfollowers", len(newUnfollowers)),
+			slog.Int("new_followers", len(newFollowers)),
+		)
+	}
 
 	return nil
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
+)
+
+// protectAccount adds username to the allow-list so it's never surfaced by
+// UnfollowCandidates. access controls whether the handle is still shown in
+// exported reports (AccessPublic) or redacted from them (AccessPrivate).
+func (app *application) protectAccount(username, reason string, access storage.Access) error {
+	store, err := storage.OpenStore(app.storeDriver())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.Protect(username, reason, access); err != nil {
+		return fmt.Errorf("protect %s: %w", username, err)
+	}
+
+	app.info("protect", "Protected account", slog.String("username", username), slog.String("reason", reason))
+	fmt.Printf("Protected @%s\n", username)
+	return nil
+}
+
+// unprotectAccount removes username from the allow-list.
+func (app *application) unprotectAccount(username string) error {
+	store, err := storage.OpenStore(app.storeDriver())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.Unprotect(username); err != nil {
+		return fmt.Errorf("unprotect %s: %w", username, err)
+	}
+
+	app.info("protect", "Unprotected account", slog.String("username", username))
+	fmt.Printf("Unprotected @%s\n", username)
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
+)
+
+// showStats prints a summary of the user's follow graph: mutuals, fans not
+// followed back, and (if churnSince is non-zero) who's been gained/lost
+// since that time, so a user can inspect their graph before running
+// unfollows.
+func (app *application) showStats(churnSince time.Time) error {
+	store, err := storage.OpenStore(app.storeDriver())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	following, err := store.AllFollowing()
+	if err != nil {
+		return fmt.Errorf("list following: %w", err)
+	}
+	followers, err := store.AllFollowers()
+	if err != nil {
+		return fmt.Errorf("list followers: %w", err)
+	}
+	mutuals, err := storage.MutualFollowers(store)
+	if err != nil {
+		return fmt.Errorf("find mutual followers: %w", err)
+	}
+	fans, err := storage.FansNotFollowedBack(store)
+	if err != nil {
+		return fmt.Errorf("find fans not followed back: %w", err)
+	}
+	candidates, err := store.UnfollowCandidates()
+	if err != nil {
+		return fmt.Errorf("list unfollow candidates: %w", err)
+	}
+	protected, err := store.ListProtected()
+	if err != nil {
+		return fmt.Errorf("list protected accounts: %w", err)
+	}
+
+	fmt.Printf("Following:          %d\n", len(following))
+	fmt.Printf("Followers:          %d\n", len(followers))
+	fmt.Printf("Mutuals:            %d\n", len(mutuals))
+	fmt.Printf("Fans (not back):    %d\n", len(fans))
+	fmt.Printf("Unfollow candidates: %d\n", len(candidates))
+	fmt.Printf("Protected accounts: %d\n", len(protected))
+
+	if !churnSince.IsZero() {
+		gained, lost, err := storage.ChurnSince(store, churnSince)
+		if err != nil {
+			return fmt.Errorf("compute churn since %s: %w", churnSince.Format(time.RFC3339), err)
+		}
+		fmt.Printf("\nSince %s:\n", churnSince.Format("2006-01-02"))
+		fmt.Printf("  New followers: %d\n", len(gained))
+		fmt.Printf("  Lost followers: %d\n", len(lost))
+	}
+
+	return nil
+}
@@ -1,202 +1,377 @@
 package main
 
 import (
-	"database/sql"
+	"bufio"
+	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/worlddrknss/instagram-unfollow-v2/pkg/browser"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/drivers"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/drivers/instagram"
+	_ "github.com/worlddrknss/instagram-unfollow-v2/pkg/drivers/threads"
 	"github.com/worlddrknss/instagram-unfollow-v2/pkg/power"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/ratelimit"
 	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
 )
 
-func (app *application) runUnfollow() error {
+// unfollowOptions configures a single `unfollow` invocation, layered on top
+// of whatever the config file/env/flags already resolved.
+type unfollowOptions struct {
+	// DryRun logs what would be unfollowed without calling the driver's
+	// Unfollow or recording anything in the store or rate limiter. The
+	// driver is still constructed and logged in.
+	DryRun bool
+	// Limit stops the run after this many successful unfollows across all
+	// accounts. Zero means unlimited.
+	Limit int
+	// Whitelist usernames are skipped for this run only, in addition to
+	// whatever's protected in the store.
+	Whitelist map[string]bool
+	// NonFollowersOnly, when true, verifies each batch of candidates against
+	// the account's live follower graph (see drivers.NonFollowerFilter)
+	// before acting, dropping anyone who follows back but hasn't been
+	// re-imported yet. Drivers that can't do this check are left unfiltered.
+	NonFollowersOnly bool
+}
+
+// loadWhitelist reads a file of usernames, one per line, ignoring blank
+// lines and lines starting with "#".
+func loadWhitelist(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	whitelist := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		whitelist[line] = true
+	}
+	return whitelist, scanner.Err()
+}
+
+// accounts returns the accounts to automate, falling back to a single
+// default Instagram account when config doesn't list any - this preserves
+// the tool's historical IG-only behavior for existing configs.
+func (app *application) accounts() []accountConfig {
+	if accounts := app.cfg().Accounts; len(accounts) > 0 {
+		return accounts
+	}
+	return []accountConfig{{Driver: instagram.Name}}
+}
+
+// filterLiveNonFollowers narrows candidates down to the ones d's live
+// follower graph (see drivers.NonFollowerFilter) still confirms don't
+// follow back, catching anyone who's followed back since the last import.
+// Drivers that don't support the live check are left unfiltered.
+func (app *application) filterLiveNonFollowers(d drivers.Driver, candidates []storage.Relationship) ([]storage.Relationship, error) {
+	filterer, ok := d.(drivers.NonFollowerFilter)
+	if !ok {
+		app.warn("unfollow", "Driver doesn't support live non-follower verification, skipping filter", slog.String("driver", d.Name()))
+		return candidates, nil
+	}
+
+	usernames := make([]string, len(candidates))
+	for i, c := range candidates {
+		usernames[i] = c.Username
+	}
+
+	nonFollowers, err := filterer.FilterNonFollowers(usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool, len(nonFollowers))
+	for _, u := range nonFollowers {
+		keep[u] = true
+	}
+
+	filtered := make([]storage.Relationship, 0, len(candidates))
+	for _, c := range candidates {
+		if keep[c.Username] {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, nil
+}
+
+func (app *application) runUnfollow(opts unfollowOptions) error {
 	// Prevent system from sleeping during automation
-	sleepInhibitor := power.NewInhibitor(app.logger)
+	sleepInhibitor := power.NewInhibitor(app.subsystemLogger("power"))
 	if err := sleepInhibitor.Start(); err != nil {
-		app.logger.Warn("Could not prevent system sleep", slog.Any("error", err))
+		app.warn("unfollow", "Could not prevent system sleep", slog.Any("error", err))
 	}
 	defer sleepInhibitor.Stop()
 
 	// Open database to get candidates
-	db, err := storage.Open("instagram.db")
+	store, err := storage.OpenStore(app.storeDriver())
 	if err != nil {
 		return err
 	}
-	defer db.Close()
+	defer store.Close()
+
+	limiter := ratelimit.NewLimiter(store, app.subsystemLogger("ratelimit"), app.actionLimits())
 
-	// Setup browser once
 	homeDir, _ := os.UserHomeDir()
-	userDataDir := filepath.Join(homeDir, ".instagram-unfollow", "chrome-profile")
 
-	cfg := browser.Config{
-		UnfollowDelaySeconds: app.config.App.UnfollowDelaySeconds,
-		MaxPerHour:           app.config.Instagram.AutomationLimits.Actions.Hourly,
-		Headless:             false, // Run visible so user can handle 2FA
-		UserDataDir:          userDataDir,
-	}
+	totalSuccessful := 0
 
-	b, err := browser.New(app.logger, cfg)
-	if err != nil {
-		return err
+	for _, acct := range app.accounts() {
+		driverName := acct.Driver
+		if driverName == "" {
+			driverName = instagram.Name
+		}
+		app.currentAccount = acct.Username
+		if app.currentAccount == "" {
+			app.currentAccount = driverName
+		}
+
+		profileDir := driverName
+		if acct.Username != "" {
+			profileDir = driverName + "-" + acct.Username
+		}
+		userDataDir := filepath.Join(homeDir, ".instagram-unfollow", profileDir+"-profile")
+		ledgerPath := filepath.Join(homeDir, ".instagram-unfollow", profileDir+"-ledger.jsonl")
+		rateLimiterStatePath := filepath.Join(homeDir, ".instagram-unfollow", profileDir+"-ratelimit.json")
+
+		cfg := app.cfg()
+		d, err := drivers.New(driverName, app.subsystemLogger(driverName), drivers.Config{
+			Store: store,
+			Browser: browser.Config{
+				UnfollowDelaySeconds: cfg.App.UnfollowDelaySeconds,
+				MaxPerHour:           cfg.Instagram.AutomationLimits.Actions.Hourly,
+				Headless:             false, // Run visible so user can handle 2FA
+				UserDataDir:          userDataDir,
+
+				// Always keep an unfollow ledger per account, so a killed
+				// run resumes instead of reprocessing everything it already
+				// resolved, and so the do-not-touch allow-list is honored.
+				LedgerPath:       ledgerPath,
+				UnfollowCooldown: time.Duration(cfg.App.UnfollowCooldownMinutes) * time.Minute,
+				AllowListPath:    cfg.App.AllowListPath,
+
+				// Always keep the adaptive rate limiter too, so an Action
+				// Blocked dialog doubles the account's pacing (persisted
+				// across restarts) instead of just stopping the session.
+				MaxPerDay:                 cfg.Instagram.AutomationLimits.Actions.Daily,
+				CooldownAfterBlockMinutes: cfg.App.CooldownAfterBlockMinutes,
+				RateLimiterStatePath:      rateLimiterStatePath,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("construct %q driver: %w", driverName, err)
+		}
+
+		successful, err := app.runUnfollowForDriver(d, store, limiter, opts, &totalSuccessful)
+		d.Close()
+		totalSuccessful += successful
+		if err != nil {
+			return fmt.Errorf("%s account: %w", driverName, err)
+		}
+
+		if opts.Limit > 0 && totalSuccessful >= opts.Limit {
+			app.info("unfollow", "Reached --limit, stopping", slog.Int("limit", opts.Limit))
+			break
+		}
 	}
-	defer b.Close()
 
+	return nil
+}
+
+// runUnfollowForDriver processes candidates until none remain, opts.Limit is
+// reached, or the platform signals it should stop. It returns how many
+// unfollows it performed (or would have, under DryRun) for this driver.
+func (app *application) runUnfollowForDriver(d drivers.Driver, store storage.Store, limiter *ratelimit.Limiter, opts unfollowOptions, totalSoFar *int) (int, error) {
 	// Wait for user to log in (will skip quickly if already logged in via persistent session)
-	app.logger.Info("Checking login status...")
-	if err := b.WaitForManualLogin(); err != nil {
-		return err
+	app.info("unfollow", "Checking login status...", slog.String("driver", d.Name()))
+	if err := d.Login(); err != nil {
+		return 0, err
 	}
 
-	hourlyLimit := app.config.Instagram.AutomationLimits.Actions.Hourly
-	delay := app.config.App.UnfollowDelaySeconds
+	ctx := context.Background()
+	driverSuccessful := 0
 
 	// Main loop - runs until no more candidates
 	for {
-		// Log how many we've already unfollowed
-		unfollowedCount, _ := storage.UnfollowedCount(db)
-		app.logger.Info("Previously unfollowed users", slog.Int("count", unfollowedCount))
-
-		// Check how many actions we've done in the last hour for rate limiting
-		actionsLastHour, err := storage.ActionsInLastHour(db, "unfollow")
-		if err != nil {
-			app.logger.Warn("Could not check recent actions", slog.Any("error", err))
-			actionsLastHour = 0
+		if opts.Limit > 0 && *totalSoFar+driverSuccessful >= opts.Limit {
+			return driverSuccessful, nil
 		}
 
-		remainingThisHour := hourlyLimit - actionsLastHour
-		if remainingThisHour < 0 {
-			remainingThisHour = 0
-		}
+		// Pick up any config hot-reload (see cmd/config_manager.go) at this
+		// action boundary, rather than only once before the loop started.
+		limiter.SetLimits(app.actionLimits())
 
-		app.logger.Info("Rate limit status",
-			slog.Int("actions_last_hour", actionsLastHour),
-			slog.Int("hourly_limit", hourlyLimit),
-			slog.Int("remaining", remainingThisHour),
-		)
+		// Log how many we've already unfollowed
+		unfollowedCount, _ := store.UnfollowedCount()
+		app.info("unfollow", "Previously unfollowed users", slog.Int("count", unfollowedCount))
 
-		// If rate limit reached, wait until next hour window
-		if remainingThisHour <= 0 {
-			waitDuration := app.calculateWaitTime(db)
-			app.logger.Info("Hourly rate limit reached, waiting for reset",
-				slog.Duration("wait_time", waitDuration),
-			)
-			time.Sleep(waitDuration)
-			continue
+		if !opts.DryRun {
+			if wait, err := limiter.Reserve(ctx, "unfollow"); err != nil {
+				return driverSuccessful, fmt.Errorf("reserve unfollow slot: %w", err)
+			} else if wait > 0 {
+				app.info("unfollow", "Rate limit reached, waiting for reset", slog.Duration("wait_time", wait))
+				time.Sleep(wait)
+				continue
+			}
 		}
 
 		// Get candidates
-		candidates, err := storage.UnfollowCandidates(db)
+		candidates, err := store.UnfollowCandidates()
 		if err != nil {
-			return err
+			return driverSuccessful, err
 		}
-		app.logger.Info("Loaded unfollow candidates", slog.Int("count", len(candidates)))
+		app.info("unfollow", "Loaded unfollow candidates", slog.Int("count", len(candidates)))
 
 		if len(candidates) == 0 {
-			app.logger.Info("No more unfollow candidates - all done!")
-			return nil
+			app.info("unfollow", "No more unfollow candidates - all done!")
+			return driverSuccessful, nil
 		}
 
-		// Process up to remaining limit
-		maxCount := remainingThisHour
-		if maxCount > len(candidates) {
-			maxCount = len(candidates)
+		if opts.NonFollowersOnly {
+			filtered, err := app.filterLiveNonFollowers(d, candidates)
+			if err != nil {
+				return driverSuccessful, fmt.Errorf("filter non-followers: %w", err)
+			}
+			app.info("unfollow", "Filtered candidates against live follower graph",
+				slog.Int("before", len(candidates)), slog.Int("after", len(filtered)))
+			candidates = filtered
+			if len(candidates) == 0 {
+				app.info("unfollow", "No more unfollow candidates - all done!")
+				return driverSuccessful, nil
+			}
 		}
 
 		successful := 0
 		skipped := 0
 		unavailable := 0
+		whitelisted := 0
+		errored := 0
 
-		for i, candidate := range candidates[:maxCount] {
+		for _, candidate := range candidates {
 			username := candidate.Username
-			result, err := b.Unfollow(username)
+			if opts.Whitelist[username] {
+				whitelisted++
+				continue
+			}
+
+			if opts.Limit > 0 && *totalSoFar+driverSuccessful+successful >= opts.Limit {
+				break
+			}
+
+			if opts.DryRun {
+				app.info("unfollow", "Would unfollow (dry run)", slog.String("username", username))
+				successful++
+				continue
+			}
+
+			wait, err := limiter.Reserve(ctx, "unfollow")
+			if err != nil {
+				return driverSuccessful + successful, fmt.Errorf("reserve unfollow slot: %w", err)
+			}
+			if wait > 0 {
+				// Hit a window cap mid-batch; stop and let the outer loop
+				// wait it out and reload candidates.
+				break
+			}
+
+			result, err := d.Unfollow(username)
 
 			switch result {
-			case browser.UnfollowSuccess:
+			case drivers.ResultSuccess:
 				// Mark as unfollowed in database
-				if err := storage.MarkUnfollowed(db, username); err != nil {
-					app.logger.Error("Failed to mark unfollowed in DB", slog.String("username", username), slog.Any("error", err))
+				if err := store.MarkUnfollowed(username); err != nil {
+					app.error("unfollow", "Failed to mark unfollowed in DB", slog.String("username", username), slog.Any("error", err))
 				}
 				// Record action for rate limiting
-				if err := storage.RecordAction(db, "unfollow", username); err != nil {
-					app.logger.Error("Failed to record action", slog.Any("error", err))
+				if err := limiter.Record("unfollow", username); err != nil {
+					app.error("unfollow", "Failed to record action", slog.Any("error", err))
 				}
 				// Remove from following table since we're no longer following
-				if err := storage.RemoveFromFollowing(db, username); err != nil {
-					app.logger.Error("Failed to remove from following table", slog.Any("error", err))
+				if err := store.RemoveFromFollowing(username); err != nil {
+					app.error("unfollow", "Failed to remove from following table", slog.Any("error", err))
 				}
 				successful++
 
-			case browser.UnfollowNotFollowing:
-				// User shows "Follow" button - we're not actually following them
-				if err := storage.MarkNotFollowing(db, username); err != nil {
-					app.logger.Error("Failed to mark not following", slog.String("username", username), slog.Any("error", err))
+			case drivers.ResultSkipped:
+				// Either the account shows "Follow" (we're not actually
+				// following) or the driver explicitly skipped it.
+				if err := store.MarkNotFollowing(username); err != nil {
+					app.error("unfollow", "Failed to mark not following", slog.String("username", username), slog.Any("error", err))
 				}
-				if err := storage.RemoveFromFollowing(db, username); err != nil {
-					app.logger.Error("Failed to remove from following table", slog.Any("error", err))
+				if err := store.RemoveFromFollowing(username); err != nil {
+					app.error("unfollow", "Failed to remove from following table", slog.Any("error", err))
 				}
 				skipped++
 				continue // Don't count against rate limit, skip delay
 
-			case browser.UnfollowProfileUnavailable:
+			case drivers.ResultUnavailable:
 				// Profile doesn't exist or was removed
-				if err := storage.RemoveFromFollowing(db, username); err != nil {
-					app.logger.Error("Failed to remove from following table", slog.Any("error", err))
+				if err := store.RemoveFromFollowing(username); err != nil {
+					app.error("unfollow", "Failed to remove from following table", slog.Any("error", err))
 				}
-				if err := storage.MarkNotFollowing(db, username); err != nil {
-					app.logger.Error("Failed to mark not following", slog.String("username", username), slog.Any("error", err))
+				if err := store.MarkNotFollowing(username); err != nil {
+					app.error("unfollow", "Failed to mark not following", slog.String("username", username), slog.Any("error", err))
 				}
 				unavailable++
 				continue // Don't count against rate limit, skip delay
 
-			case browser.UnfollowError:
-				app.logger.Error("Failed to unfollow", slog.String("username", username), slog.Any("error", err))
+			case drivers.ResultError:
+				app.error("unfollow", "Failed to unfollow", slog.String("username", username), slog.Any("error", err))
+				errored++
 				continue
-			}
 
-			// Check hourly limit
-			if successful >= remainingThisHour {
-				app.logger.Info("Reached hourly limit", slog.Int("count", successful))
-				break
-			}
+			case drivers.ResultRateLimited:
+				app.warn("unfollow", "Platform rate limited the unfollow request, stopping session", slog.String("username", username), slog.Any("error", err))
+				return driverSuccessful + successful, nil
+
+			case drivers.ResultChallengeRequired:
+				app.error("unfollow", "Platform requires a challenge to continue, stopping session", slog.String("username", username), slog.Any("error", err))
+				return driverSuccessful + successful, fmt.Errorf("challenge required: %w", err)
 
-			// Delay between unfollows (except after last one)
-			if i < maxCount-1 {
-				app.logger.Info("Waiting before next unfollow", slog.Int("delay_seconds", delay))
-				time.Sleep(time.Duration(delay) * time.Second)
+			case drivers.ResultActionBlocked:
+				app.error("unfollow", "Platform blocked unfollow actions, stopping session", slog.String("username", username), slog.Any("error", err))
+				return driverSuccessful + successful, nil
 			}
 		}
 
-		app.logger.Info("Session batch complete",
+		driverSuccessful += successful
+
+		app.info("unfollow", "Session batch complete",
 			slog.Int("unfollowed", successful),
 			slog.Int("skipped_not_following", skipped),
 			slog.Int("profiles_unavailable", unavailable),
+			slog.Int("whitelisted", whitelisted),
+			slog.Int("errored", errored),
 		)
 
-		// If we hit the limit, loop will check and wait for reset
-		// If we didn't hit the limit, we've processed all remaining candidates
-	}
-}
-
-// calculateWaitTime determines how long to wait until rate limit resets
-func (app *application) calculateWaitTime(db *sql.DB) time.Duration {
-	// Get the oldest action in the last hour - that's when one slot will free up
-	oldest, err := storage.OldestActionInLastHour(db, "unfollow")
-	if err != nil || oldest == 0 {
-		// If we can't determine, wait 5 minutes and check again
-		return 5 * time.Minute
-	}
+		if opts.DryRun {
+			// Dry runs never mark candidates handled, so re-querying
+			// candidates would just return the same list forever.
+			return driverSuccessful, nil
+		}
 
-	// Calculate when that action will be more than 1 hour old
-	oldestTime := time.Unix(oldest, 0)
-	freeAt := oldestTime.Add(1*time.Hour + 1*time.Minute) // Add 1 min buffer
-	waitDuration := time.Until(freeAt)
+		if successful == 0 && skipped == 0 && unavailable == 0 && whitelisted+errored == len(candidates) {
+			// Nothing in this pass got marked handled in the store or
+			// recorded against the limiter - whether because every
+			// candidate is whitelisted, every attempt errored, or some mix
+			// of the two - so another pass would just see the same set
+			// forever. Nothing left this driver can do.
+			app.info("unfollow", "No progress possible on remaining candidates, stopping",
+				slog.Int("whitelisted", whitelisted), slog.Int("errored", errored))
+			return driverSuccessful, nil
+		}
 
-	if waitDuration < 1*time.Minute {
-		waitDuration = 1 * time.Minute
+		// If we hit a window cap, the outer loop's Reserve call will wait
+		// for it to free up. If we didn't, we've processed every candidate.
 	}
-
-	return waitDuration
 }
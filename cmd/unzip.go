@@ -1,7 +1,9 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
 	"log/slog"
 
@@ -9,7 +11,7 @@ import (
 )
 
 func (app *application) unzipData(zipPath string) (string, error) {
-	destDir := app.config.App.ExtractedPath
+	destDir := app.cfg().App.ExtractedPath
 	if destDir == "" {
 		var err error
 		destDir, err = os.MkdirTemp("", "instagram-extracted-*")
@@ -18,9 +20,20 @@ func (app *application) unzipData(zipPath string) (string, error) {
 		}
 	}
 
-	app.logger.Info("Unzipping data", slog.String("zipPath", zipPath), slog.String("destDir", destDir))
-	if err := extraction.Unzip(zipPath, destDir); err != nil {
-		return "", err
+	app.info("parser", "Unzipping data", slog.String("zipPath", zipPath), slog.String("destDir", destDir))
+
+	var opts extraction.UnzipOptions
+	switch {
+	case strings.HasSuffix(zipPath, ".tar.gz") || strings.HasSuffix(zipPath, ".tgz"):
+		if err := extraction.UntarGz(zipPath, destDir, opts); err != nil {
+			return "", err
+		}
+	case strings.HasSuffix(zipPath, ".zip"):
+		if err := extraction.Unzip(zipPath, destDir, opts); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported archive format: %s", zipPath)
 	}
 
 	return destDir, nil
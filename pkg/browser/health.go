@@ -0,0 +1,109 @@
+package browser
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// SessionHealthStatus describes what SessionHealth found on the current
+// page.
+type SessionHealthStatus int
+
+const (
+	// SessionHealthy means the current page shows no sign of a lost or
+	// interrupted session.
+	SessionHealthy SessionHealthStatus = iota
+	// SessionLoggedOut means Instagram is showing its login form.
+	SessionLoggedOut
+	// SessionChallengeRequired means Instagram is showing a checkpoint,
+	// 2FA, or other challenge page that needs a human to clear.
+	SessionChallengeRequired
+	// SessionSuspiciousLogin means Instagram is showing a "suspicious
+	// login attempt" / "was this you" interstitial.
+	SessionSuspiciousLogin
+)
+
+// sessionHealthScript returns 'logged_out', 'challenge', 'suspicious', or
+// 'healthy' based on DOM markers Instagram shows for each of those states.
+const sessionHealthScript = `
+(function() {
+	const text = document.body.innerText || '';
+
+	if (text.includes('Enter the code') ||
+		text.includes('Enter Security Code') ||
+		text.includes('two-factor') ||
+		text.includes('Two-Factor') ||
+		text.includes('enter the confirmation code')) {
+		return 'challenge';
+	}
+	if (text.includes('suspicious login attempt') ||
+		text.includes('We detected an unusual login attempt') ||
+		text.includes('This Was Me')) {
+		return 'suspicious';
+	}
+	if (document.querySelector('a[href="/accounts/login/"]') !== null ||
+		document.querySelector('input[name="username"]') !== null) {
+		return 'logged_out';
+	}
+	return 'healthy';
+})()
+`
+
+// SessionHealth probes the current page for signs the session has gone
+// bad: logged out, a checkpoint/challenge page, a 2FA prompt, or a
+// "suspicious login" interstitial. It also checks the URL for Instagram's
+// own /challenge/ path, which some challenge pages use even when the DOM
+// text doesn't obviously say so.
+func (b *Browser) SessionHealth() (SessionHealthStatus, error) {
+	var url, status string
+	err := chromedp.Run(b.ctx,
+		chromedp.Location(&url),
+		chromedp.Evaluate(sessionHealthScript, &status),
+	)
+	if err != nil {
+		return SessionHealthy, fmt.Errorf("probe session health: %w", err)
+	}
+
+	if strings.Contains(url, "/challenge/") {
+		return SessionChallengeRequired, nil
+	}
+
+	switch status {
+	case "challenge":
+		return SessionChallengeRequired, nil
+	case "suspicious":
+		return SessionSuspiciousLogin, nil
+	case "logged_out":
+		return SessionLoggedOut, nil
+	default:
+		return SessionHealthy, nil
+	}
+}
+
+// recoverSession is called by unfollowOnce when SessionHealth reports a
+// problem before an unfollow attempt. A logged-out session is recovered by
+// re-running WaitForManualLogin and refreshing the on-disk cookie jar (if
+// Config.CookiePath is set); a challenge or suspicious-login interstitial
+// needs a human in the loop, so it's reported rather than retried.
+func (b *Browser) recoverSession(status SessionHealthStatus) error {
+	switch status {
+	case SessionChallengeRequired, SessionSuspiciousLogin:
+		return fmt.Errorf("session needs manual intervention (challenge or suspicious-login interstitial)")
+	case SessionLoggedOut:
+		b.logger.Warn("Session logged out, attempting re-authentication")
+		if err := b.WaitForManualLogin(); err != nil {
+			return fmt.Errorf("re-authenticate after session loss: %w", err)
+		}
+		if b.config.CookiePath != "" {
+			if err := b.SaveCookies(b.config.CookiePath); err != nil {
+				b.logger.Warn("Failed to refresh saved cookies after re-login", slog.Any("error", err))
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,120 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+)
+
+// point is a pointer position in viewport coordinates.
+type point struct{ x, y float64 }
+
+// humanClick drives real Input.dispatchMouseEvent CDP commands instead of
+// JS-dispatched MouseEvents, so clicks generate trusted events and genuine
+// CDP traffic rather than isTrusted === false events that never touch the
+// browser's input pipeline. The pointer travels from the browser's last
+// known position to a jittered point inside selector's bounding box along a
+// Bezier arc before pressing and releasing the mouse button.
+func (b *Browser) humanClick(selector string, opts ...chromedp.QueryOption) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		var nodes []*cdp.Node
+		if err := chromedp.Nodes(selector, &nodes, opts...).Do(ctx); err != nil {
+			return fmt.Errorf("find element %s: %w", selector, err)
+		}
+		if len(nodes) == 0 {
+			return fmt.Errorf("element not found: %s", selector)
+		}
+
+		model, err := dom.GetBoxModel().WithNodeID(nodes[0].NodeID).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("get box model: %w", err)
+		}
+		if len(model.Content) < 8 {
+			return fmt.Errorf("unexpected box model for %s", selector)
+		}
+
+		left, top := model.Content[0], model.Content[1]
+		right, bottom := model.Content[4], model.Content[5]
+		target := point{
+			x: left + (right-left)*(0.3+rand.Float64()*0.4),
+			y: top + (bottom-top)*(0.3+rand.Float64()*0.4),
+		}
+
+		start := b.lastPointer
+		if start == (point{}) {
+			start = point{x: left + (right-left)/2, y: top + (bottom-top)/2}
+		}
+
+		if err := moveAlongBezier(ctx, start, target); err != nil {
+			return err
+		}
+
+		if err := input.DispatchMouseEvent(input.MousePressed, target.x, target.y).
+			WithButton(input.Left).WithClickCount(1).Do(ctx); err != nil {
+			return fmt.Errorf("mouse pressed: %w", err)
+		}
+
+		time.Sleep(time.Duration(40+rand.Intn(80)) * time.Millisecond)
+
+		if err := input.DispatchMouseEvent(input.MouseReleased, target.x, target.y).
+			WithButton(input.Left).WithClickCount(1).Do(ctx); err != nil {
+			return fmt.Errorf("mouse released: %w", err)
+		}
+
+		b.lastPointer = target
+		return nil
+	}
+}
+
+// moveAlongBezier walks the pointer from start to end in 20-40 steps of
+// variable duration, dispatching a trusted "mouseMoved" event per step. The
+// path bows away from the straight line by a random 20-80px offset so it
+// traces a natural arc instead of a robotic straight line.
+func moveAlongBezier(ctx context.Context, start, end point) error {
+	steps := 20 + rand.Intn(21)
+	dx, dy := end.x-start.x, end.y-start.y
+
+	var perpX, perpY float64
+	if dist := math.Hypot(dx, dy); dist > 0 {
+		perpX, perpY = -dy/dist, dx/dist
+	}
+	offset := 20 + rand.Float64()*60
+	if rand.Intn(2) == 0 {
+		offset = -offset
+	}
+
+	ctrl1 := point{x: start.x + dx*0.3 + perpX*offset, y: start.y + dy*0.3 + perpY*offset}
+	ctrl2 := point{x: start.x + dx*0.7 + perpX*offset, y: start.y + dy*0.7 + perpY*offset}
+
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		p := cubicBezier(start, ctrl1, ctrl2, end, t)
+
+		// Small per-step jitter so consecutive moves aren't perfectly smooth.
+		p.x += (rand.Float64() - 0.5) * 2
+		p.y += (rand.Float64() - 0.5) * 2
+
+		if err := input.DispatchMouseEvent(input.MouseMoved, p.x, p.y).Do(ctx); err != nil {
+			return fmt.Errorf("mouse moved: %w", err)
+		}
+
+		time.Sleep(time.Duration(10+rand.Intn(16)) * time.Millisecond)
+	}
+
+	return nil
+}
+
+func cubicBezier(p0, p1, p2, p3 point, t float64) point {
+	u := 1 - t
+	return point{
+		x: u*u*u*p0.x + 3*u*u*t*p1.x + 3*u*t*t*p2.x + t*t*t*p3.x,
+		y: u*u*u*p0.y + 3*u*u*t*p1.y + 3*u*t*t*p2.y + t*t*t*p3.y,
+	}
+}
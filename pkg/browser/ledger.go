@@ -0,0 +1,180 @@
+package browser
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LedgerEntry records the outcome of a single attempted unfollow.
+type LedgerEntry struct {
+	Username  string         `json:"username"`
+	Result    UnfollowResult `json:"result"`
+	Error     string         `json:"error,omitempty"`
+	Timestamp int64          `json:"timestamp"`
+}
+
+// terminal reports whether result means username never needs to be
+// reprocessed, regardless of cooldown.
+func (e LedgerEntry) terminal() bool {
+	switch e.Result {
+	case UnfollowSuccess, UnfollowNotFollowing, UnfollowProfileUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnfollowLedger is an append-only, JSON-lines log of every unfollow
+// attempt (username, timestamp, result code, error), consulted by Unfollow
+// before acting so a killed run can resume without re-processing accounts
+// it already resolved, and so a cooldown can keep a recently-unfollowed
+// account from being re-processed if it re-appears in an input list.
+type UnfollowLedger struct {
+	mu      sync.Mutex
+	file    *os.File
+	history []LedgerEntry
+	latest  map[string]LedgerEntry
+	allowed map[string]bool // do-not-touch allow-list, loaded once from a file
+}
+
+// NewUnfollowLedger opens (creating if necessary) a JSON-lines ledger file
+// at path, replaying any existing entries so ShouldSkip reflects prior runs.
+func NewUnfollowLedger(path string) (*UnfollowLedger, error) {
+	l := &UnfollowLedger{
+		latest:  make(map[string]LedgerEntry),
+		allowed: make(map[string]bool),
+	}
+
+	if err := l.replay(path); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("open unfollow ledger: %w", err)
+	}
+	l.file = f
+
+	return l, nil
+}
+
+func (l *UnfollowLedger) replay(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read unfollow ledger: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry LedgerEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return fmt.Errorf("parse unfollow ledger: %w", err)
+		}
+		l.history = append(l.history, entry)
+		l.latest[entry.Username] = entry
+	}
+	return scanner.Err()
+}
+
+// LoadAllowList reads a do-not-touch list (one username per line, blank
+// lines and "#"-prefixed comments ignored) that ShouldSkip always honors.
+func (l *UnfollowLedger) LoadAllowList(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read unfollow allow-list: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		l.allowed[line] = true
+	}
+	return nil
+}
+
+// ShouldSkip reports whether username should be skipped: it's on the
+// allow-list, was already terminally resolved by a prior run, or was
+// attempted more recently than cooldown ago.
+func (l *UnfollowLedger) ShouldSkip(username string, cooldown time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.allowed[username] {
+		return true
+	}
+
+	entry, ok := l.latest[username]
+	if !ok {
+		return false
+	}
+	if entry.terminal() {
+		return true
+	}
+	if cooldown > 0 && time.Since(time.Unix(entry.Timestamp, 0)) < cooldown {
+		return true
+	}
+	return false
+}
+
+// Record appends a new entry for username to the ledger.
+func (l *UnfollowLedger) Record(username string, result UnfollowResult, resultErr error) error {
+	entry := LedgerEntry{Username: username, Result: result, Timestamp: time.Now().Unix()}
+	if resultErr != nil {
+		entry.Error = resultErr.Error()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal unfollow ledger entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write unfollow ledger: %w", err)
+	}
+	l.history = append(l.history, entry)
+	l.latest[username] = entry
+
+	return nil
+}
+
+// Export writes every recorded attempt, in chronological order, to path as
+// an indented JSON array for auditing.
+func (l *UnfollowLedger) Export(path string) error {
+	l.mu.Lock()
+	data, err := json.MarshalIndent(l.history, "", "  ")
+	l.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshal unfollow ledger export: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Close closes the underlying ledger file.
+func (l *UnfollowLedger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,112 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// LocaleStrings holds the follow/following/unfollow button text Instagram's
+// web UI shows in a given locale, so Unfollow's DOM checks and click
+// targets don't silently fail for anyone not running Instagram in English.
+type LocaleStrings struct {
+	Follow    string
+	Following string
+	Unfollow  string
+}
+
+// localeTable maps the primary subtag of document.documentElement.lang
+// (e.g. "es" from "es-ES") to Instagram's button text in that locale.
+var localeTable = map[string]LocaleStrings{
+	"en": {Follow: "Follow", Following: "Following", Unfollow: "Unfollow"},
+	"es": {Follow: "Seguir", Following: "Siguiendo", Unfollow: "Dejar de seguir"},
+	"tr": {Follow: "Takip Et", Following: "Takip Ediliyor", Unfollow: "Takibi Bırak"},
+	"pt": {Follow: "Seguir", Following: "Seguindo", Unfollow: "Deixar de seguir"},
+	"de": {Follow: "Folgen", Following: "Gefolgt", Unfollow: "Nicht mehr folgen"},
+	"fr": {Follow: "Suivre", Following: "Abonné(e)", Unfollow: "Ne plus suivre"},
+	"it": {Follow: "Segui", Following: "Segui già", Unfollow: "Smetti di seguire"},
+	"ja": {Follow: "フォローする", Following: "フォロー中", Unfollow: "フォローをやめる"},
+	"ko": {Follow: "팔로우", Following: "팔로잉", Unfollow: "팔로우 취소"},
+	"id": {Follow: "Ikuti", Following: "Mengikuti", Unfollow: "Berhenti Mengikuti"},
+}
+
+// defaultLocale is used when detection fails or the detected language isn't
+// in localeTable and no Config.LocaleOverride is set.
+var defaultLocale = localeTable["en"]
+
+// resolveLocale returns the button text for lang (a BCP-47 tag such as
+// "es-ES" or "pt-BR"), matched by its primary subtag, falling back to
+// English for anything unrecognized.
+func resolveLocale(lang string) LocaleStrings {
+	primary := lang
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		primary = lang[:i]
+	}
+	if strs, ok := localeTable[strings.ToLower(primary)]; ok {
+		return strs
+	}
+	return defaultLocale
+}
+
+// detectLocale resolves b.locale once: Config.LocaleOverride if set,
+// otherwise document.documentElement.lang on the current page looked up
+// against localeTable. Later calls are no-ops.
+func (b *Browser) detectLocale(ctx context.Context) error {
+	if b.locale != nil {
+		return nil
+	}
+	if b.config.LocaleOverride != nil {
+		b.locale = b.config.LocaleOverride
+		return nil
+	}
+
+	var lang string
+	if err := chromedp.Evaluate(`document.documentElement.lang || ''`, &lang).Do(ctx); err != nil {
+		return fmt.Errorf("detect page language: %w", err)
+	}
+	resolved := resolveLocale(lang)
+	b.locale = &resolved
+	return nil
+}
+
+// followStatusScript returns the JS used by Unfollow to determine whether a
+// profile's follow button currently reads "Following" or "Follow" in
+// locale, returning 'following', 'not_following', or 'unknown'.
+func followStatusScript(locale LocaleStrings) string {
+	const tmpl = `
+(function() {
+	function check(elements) {
+		for (const el of elements) {
+			const text = el.textContent.trim();
+			if (text === %[1]q || text.includes(%[1]q)) {
+				return 'following';
+			}
+			if (text === %[2]q && !text.includes(%[1]q)) {
+				return 'not_following';
+			}
+		}
+		return null;
+	}
+	return check(document.querySelectorAll('button')) ||
+		check(document.querySelectorAll('div[role="button"]')) ||
+		'unknown';
+})()
+`
+	return fmt.Sprintf(tmpl, locale.Following, locale.Follow)
+}
+
+// followingButtonXPath returns the XPath humanClick uses to find the
+// "Following" button/div in locale.
+func followingButtonXPath(locale LocaleStrings) string {
+	return fmt.Sprintf(`//button[contains(text(),"%s")] | //div[@role="button"][contains(text(),"%s")]`,
+		locale.Following, locale.Following)
+}
+
+// unfollowConfirmButtonXPath returns the XPath humanClick uses to find the
+// "Unfollow" confirmation button/div in locale.
+func unfollowConfirmButtonXPath(locale LocaleStrings) string {
+	return fmt.Sprintf(`//button[normalize-space(text())="%s"] | //button/span[normalize-space(text())="%s"]/parent::button | //div[@role="button"]/span[normalize-space(text())="%s"]/parent::div`,
+		locale.Unfollow, locale.Unfollow, locale.Unfollow)
+}
@@ -0,0 +1,134 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// unfollowEndpoint matches Instagram's unfollow API routes, e.g.
+// /api/v1/friendships/12345/unfollow/ or /api/v1/web/friendships/12345/unfollow/.
+var unfollowEndpoint = regexp.MustCompile(`/api/v1/(web/)?friendships/[^/]+/unfollow/`)
+
+// UnfollowAPIResult captures what Instagram's unfollow endpoint actually
+// returned, since the DOM can show a normal button transition even when the
+// request was silently rate limited or challenged.
+type UnfollowAPIResult struct {
+	StatusCode        int64
+	Message           string
+	Spam              bool
+	FeedbackRequired  bool
+	ChallengeRequired bool
+	Headers           map[string]string // x-ig-set-* response headers
+}
+
+// unfollowResponseBody is the subset of Instagram's JSON response body we
+// care about for detecting rate limits and blocks.
+type unfollowResponseBody struct {
+	Message           string `json:"message"`
+	Spam              bool   `json:"spam"`
+	FeedbackRequired  bool   `json:"feedback_required"`
+	ChallengeRequired bool   `json:"challenge_required"`
+}
+
+// watchUnfollowRequests enables the Network domain and listens for responses
+// to Instagram's unfollow endpoints, delivering parsed results on
+// b.unfollowResults so Unfollow can await the real API outcome instead of
+// inferring it from the DOM.
+func (b *Browser) watchUnfollowRequests() error {
+	if err := chromedp.Run(b.ctx, network.Enable()); err != nil {
+		return fmt.Errorf("enable network domain: %w", err)
+	}
+
+	var mu sync.Mutex
+	pending := make(map[network.RequestID]*network.Response)
+
+	chromedp.ListenTarget(b.ctx, func(ev interface{}) {
+		switch e := ev.(type) {
+		case *network.EventResponseReceived:
+			if e.Response == nil || !unfollowEndpoint.MatchString(e.Response.URL) {
+				return
+			}
+			mu.Lock()
+			pending[e.RequestID] = e.Response
+			mu.Unlock()
+
+		case *network.EventLoadingFinished:
+			mu.Lock()
+			resp, ok := pending[e.RequestID]
+			if ok {
+				delete(pending, e.RequestID)
+			}
+			mu.Unlock()
+			if !ok {
+				return
+			}
+			go b.deliverUnfollowResult(resp, e.RequestID)
+		}
+	})
+
+	return nil
+}
+
+func (b *Browser) deliverUnfollowResult(resp *network.Response, reqID network.RequestID) {
+	var body []byte
+	err := chromedp.Run(b.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		body, err = network.GetResponseBody(reqID).Do(ctx)
+		return err
+	}))
+	if err != nil {
+		b.logger.Warn("Could not read unfollow response body", slog.Any("error", err))
+		return
+	}
+
+	var parsed unfollowResponseBody
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		b.logger.Warn("Could not parse unfollow response body", slog.Any("error", err))
+	}
+
+	headers := make(map[string]string)
+	for k, v := range resp.Headers {
+		if !strings.HasPrefix(strings.ToLower(k), "x-ig-set-") {
+			continue
+		}
+		if s, ok := v.(string); ok {
+			headers[k] = s
+		}
+	}
+
+	result := UnfollowAPIResult{
+		StatusCode:        resp.Status,
+		Message:           parsed.Message,
+		Spam:              parsed.Spam,
+		FeedbackRequired:  parsed.FeedbackRequired,
+		ChallengeRequired: parsed.ChallengeRequired || parsed.Message == "challenge_required",
+		Headers:           headers,
+	}
+
+	select {
+	case b.unfollowResults <- result:
+	default:
+		b.logger.Warn("Dropping unfollow API result, channel full")
+	}
+}
+
+// drainUnfollowResults discards any result already buffered on
+// b.unfollowResults, so a late response from a previous unfollow attempt
+// can't be mistaken for the next one's.
+func (b *Browser) drainUnfollowResults() {
+	for {
+		select {
+		case <-b.unfollowResults:
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/chromedp/chromedp"
+)
+
+// igAppID is the x-ig-app-id Instagram's own web client sends on its
+// internal API calls; GraphQL/API requests without it are more likely to be
+// flagged as non-browser traffic.
+const igAppID = "936619743392459"
+
+// followersQueryHash and followingQueryHash select which edge Instagram's
+// GraphQL endpoint returns for a given user id. These are stable per web
+// client build but do get rotated occasionally, so they may need updating
+// if Instagram ships a new one.
+const (
+	followersQueryHash = "c76146de99bb02f6415203be841dd25a"
+	followingQueryHash = "d04b0a864b4b54837c0d870b0e77e076"
+)
+
+// edgeConnection is the shape of a paginated edge_followed_by/edge_follow
+// connection in Instagram's GraphQL responses.
+type edgeConnection struct {
+	PageInfo struct {
+		HasNextPage bool   `json:"has_next_page"`
+		EndCursor   string `json:"end_cursor"`
+	} `json:"page_info"`
+	Edges []struct {
+		Node struct {
+			Username string `json:"username"`
+		} `json:"node"`
+	} `json:"edges"`
+}
+
+// resolveUserIDScript looks up a username's numeric id via the same
+// web_profile_info endpoint instagram.com's own profile page uses.
+const resolveUserIDScript = `
+(async () => {
+	const res = await fetch("https://www.instagram.com/api/v1/users/web_profile_info/?username=%s", {
+		credentials: 'include',
+		headers: { 'x-ig-app-id': '%s' },
+	});
+	if (!res.ok) {
+		throw new Error('profile lookup failed: ' + res.status);
+	}
+	const body = await res.json();
+	return body.data.user.id;
+})()
+`
+
+// graphQLEdgesScript fetches one page of a follower/following edge
+// connection, attaching the csrftoken cookie as a header the way
+// instagram.com's own JS does for its authenticated GraphQL calls.
+const graphQLEdgesScript = `
+(async () => {
+	const csrftoken = (document.cookie.match(/csrftoken=([^;]+)/) || [])[1] || '';
+	const variables = JSON.stringify({ id: "%s", first: 50, after: "%s" });
+	const url = "https://www.instagram.com/graphql/query/?query_hash=%s&variables=" + encodeURIComponent(variables);
+	const res = await fetch(url, {
+		credentials: 'include',
+		headers: { 'x-csrftoken': csrftoken, 'x-ig-app-id': '%s' },
+	});
+	if (!res.ok) {
+		throw new Error('graphql request failed: ' + res.status);
+	}
+	return await res.json();
+})()
+`
+
+// loggedInUserIDScript reads the numeric id of the currently logged-in user
+// straight out of the ds_user_id cookie instagram.com's own client sets on
+// login, rather than round-tripping through a profile lookup for "ourself".
+const loggedInUserIDScript = `(document.cookie.match(/ds_user_id=([^;]+)/) || [])[1] || ''`
+
+// resolveUserID looks up username's numeric Instagram user id.
+func (b *Browser) resolveUserID(username string) (string, error) {
+	var id string
+	script := fmt.Sprintf(resolveUserIDScript, username, igAppID)
+	if err := chromedp.Run(b.ctx, chromedp.Evaluate(script, &id, withAwaitPromise)); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// loggedInUserID returns the numeric user id of the currently logged-in
+// session, failing if no session cookie is present.
+func (b *Browser) loggedInUserID() (string, error) {
+	var id string
+	if err := chromedp.Run(b.ctx, chromedp.Evaluate(loggedInUserIDScript, &id)); err != nil {
+		return "", fmt.Errorf("read ds_user_id cookie: %w", err)
+	}
+	if id == "" {
+		return "", fmt.Errorf("ds_user_id cookie not found; is the session logged in?")
+	}
+	return id, nil
+}
+
+// fetchGraphQLEdges walks every page of the edgeField connection (e.g.
+// "edge_followed_by" or "edge_follow") for userID, following has_next_page/
+// end_cursor until exhausted, and returns every username encountered.
+func (b *Browser) fetchGraphQLEdges(userID, queryHash, edgeField string) ([]string, error) {
+	var usernames []string
+	cursor := ""
+
+	for page := 0; ; page++ {
+		if page > 0 {
+			b.randomDelay(300, 900)
+		}
+
+		var raw []byte
+		script := fmt.Sprintf(graphQLEdgesScript, userID, cursor, queryHash, igAppID)
+		if err := chromedp.Run(b.ctx, chromedp.Evaluate(script, &raw, withAwaitPromise)); err != nil {
+			return nil, err
+		}
+
+		var envelope struct {
+			Data struct {
+				User map[string]json.RawMessage `json:"user"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(raw, &envelope); err != nil {
+			return nil, fmt.Errorf("parse graphql response: %w", err)
+		}
+
+		edgeRaw, ok := envelope.Data.User[edgeField]
+		if !ok {
+			return nil, fmt.Errorf("graphql response missing %s", edgeField)
+		}
+		var edges edgeConnection
+		if err := json.Unmarshal(edgeRaw, &edges); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", edgeField, err)
+		}
+
+		for _, e := range edges.Edges {
+			usernames = append(usernames, e.Node.Username)
+		}
+
+		if !edges.PageInfo.HasNextPage || edges.PageInfo.EndCursor == "" {
+			break
+		}
+		cursor = edges.PageInfo.EndCursor
+	}
+
+	return usernames, nil
+}
+
+// ListNonFollowers returns the accounts username follows that don't follow
+// them back, by paginating Instagram's own follower/following GraphQL edges
+// rather than relying on a locally imported snapshot. Callers that maintain
+// an allow-list (e.g. storage.ListProtected) should filter the result
+// themselves before acting on it.
+func (b *Browser) ListNonFollowers(username string) ([]string, error) {
+	userID, err := b.resolveUserID(username)
+	if err != nil {
+		return nil, fmt.Errorf("resolve user id for %s: %w", username, err)
+	}
+
+	followers, err := b.fetchGraphQLEdges(userID, followersQueryHash, "edge_followed_by")
+	if err != nil {
+		return nil, fmt.Errorf("list followers for %s: %w", username, err)
+	}
+	following, err := b.fetchGraphQLEdges(userID, followingQueryHash, "edge_follow")
+	if err != nil {
+		return nil, fmt.Errorf("list following for %s: %w", username, err)
+	}
+
+	followerSet := make(map[string]bool, len(followers))
+	for _, u := range followers {
+		followerSet[u] = true
+	}
+
+	nonFollowers := make([]string, 0, len(following))
+	for _, u := range following {
+		if !followerSet[u] {
+			nonFollowers = append(nonFollowers, u)
+		}
+	}
+	sort.Strings(nonFollowers)
+
+	return nonFollowers, nil
+}
+
+// FilterNonFollowers narrows usernames down to the ones that don't follow
+// the logged-in user back, checked live against Instagram's follower graph
+// rather than a possibly-stale imported snapshot.
+func (b *Browser) FilterNonFollowers(usernames []string) ([]string, error) {
+	ownID, err := b.loggedInUserID()
+	if err != nil {
+		return nil, err
+	}
+
+	followers, err := b.fetchGraphQLEdges(ownID, followersQueryHash, "edge_followed_by")
+	if err != nil {
+		return nil, fmt.Errorf("list followers: %w", err)
+	}
+	followerSet := make(map[string]bool, len(followers))
+	for _, u := range followers {
+		followerSet[u] = true
+	}
+
+	nonFollowers := make([]string, 0, len(usernames))
+	for _, u := range usernames {
+		if !followerSet[u] {
+			nonFollowers = append(nonFollowers, u)
+		}
+	}
+
+	return nonFollowers, nil
+}
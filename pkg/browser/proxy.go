@@ -0,0 +1,118 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// proxyServerFlag returns the "host:port" (or "scheme://host:port") value
+// Chrome's --proxy-server flag expects, with any userinfo stripped since
+// Chrome rejects credentials embedded in the flag itself - those are instead
+// supplied via Fetch.authRequired below.
+func proxyServerFlag(proxyURL string) (string, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return "", fmt.Errorf("parse proxy URL: %w", err)
+	}
+	u.User = nil
+	return u.String(), nil
+}
+
+// watchProxyAuth enables the Fetch domain and answers Chrome's proxy
+// authentication challenges with the credentials embedded in proxyURL, so
+// authenticated proxies work without a login prompt blocking navigation.
+func (b *Browser) watchProxyAuth(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("parse proxy URL: %w", err)
+	}
+	if u.User == nil {
+		return nil
+	}
+	username := u.User.Username()
+	password, _ := u.User.Password()
+
+	if err := chromedp.Run(b.ctx, fetch.Enable().WithHandleAuthRequests(true)); err != nil {
+		return fmt.Errorf("enable fetch domain: %w", err)
+	}
+
+	chromedp.ListenTarget(b.ctx, func(ev interface{}) {
+		switch e := ev.(type) {
+		case *fetch.EventAuthRequired:
+			go func() {
+				err := chromedp.Run(b.ctx, fetch.ContinueWithAuth(e.RequestID, &fetch.AuthChallengeResponse{
+					Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+					Username: username,
+					Password: password,
+				}))
+				if err != nil {
+					b.logger.Warn("Could not answer proxy auth challenge", slog.Any("error", err))
+				}
+			}()
+
+		case *fetch.EventRequestPaused:
+			go func() {
+				if err := chromedp.Run(b.ctx, fetch.ContinueRequest(e.RequestID)); err != nil {
+					b.logger.Warn("Could not continue paused request", slog.Any("error", err))
+				}
+			}()
+		}
+	})
+
+	return nil
+}
+
+// Rotate tears down the current browser allocator and rebuilds it against
+// the next proxy from cfg.ProxyRotator, carrying cookies across so the
+// Instagram session survives the IP change.
+func (b *Browser) Rotate() error {
+	if b.config.ProxyRotator == nil {
+		return fmt.Errorf("no ProxyRotator configured")
+	}
+
+	var cookies []*network.Cookie
+	if err := chromedp.Run(b.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		cookies, err = network.GetCookies().Do(ctx)
+		return err
+	})); err != nil {
+		return fmt.Errorf("save cookies before rotation: %w", err)
+	}
+
+	cfg := b.config
+	cfg.ProxyURL = b.config.ProxyRotator()
+
+	fresh, err := New(b.logger, cfg)
+	if err != nil {
+		return fmt.Errorf("start browser on rotated proxy: %w", err)
+	}
+
+	if err := chromedp.Run(fresh.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		params := make([]*network.CookieParam, 0, len(cookies))
+		for _, c := range cookies {
+			params = append(params, &network.CookieParam{
+				Name:     c.Name,
+				Value:    c.Value,
+				Domain:   c.Domain,
+				Path:     c.Path,
+				Secure:   c.Secure,
+				HTTPOnly: c.HTTPOnly,
+				SameSite: c.SameSite,
+			})
+		}
+		return network.SetCookies(params).Do(ctx)
+	})); err != nil {
+		fresh.Close()
+		return fmt.Errorf("restore cookies after rotation: %w", err)
+	}
+
+	b.cancel()
+	*b = *fresh
+	return nil
+}
@@ -0,0 +1,148 @@
+package browser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// RateLimiterState is the on-disk shape of RateLimiter's pacing and recent
+// activity, so both survive a process restart.
+type RateLimiterState struct {
+	// BaseDelaySeconds is the current per-unfollow delay. RegisterBlock
+	// doubles it every time Instagram shows an Action Blocked dialog.
+	BaseDelaySeconds int `json:"base_delay_seconds"`
+	// Unfollows holds the unix timestamp of every successful unfollow,
+	// pruned to the trailing 24 hours on each save.
+	Unfollows []int64 `json:"unfollows"`
+}
+
+// RateLimiter paces Unfollow against real per-hour/per-day quotas that
+// persist across restarts, and backs off adaptively when Instagram signals
+// it's blocking the account's actions. It depends on nothing from chromedp,
+// so it can be unit-tested on its own.
+type RateLimiter struct {
+	mu   sync.Mutex
+	path string
+	now  func() time.Time
+
+	maxPerHour int
+	maxPerDay  int
+	cooldown   time.Duration
+
+	state RateLimiterState
+}
+
+// NewRateLimiter loads path (if it exists) and returns a RateLimiter seeded
+// with baseDelaySeconds when no prior state is on disk.
+func NewRateLimiter(path string, baseDelaySeconds, maxPerHour, maxPerDay int, cooldown time.Duration) (*RateLimiter, error) {
+	r := &RateLimiter{
+		path:       path,
+		now:        time.Now,
+		maxPerHour: maxPerHour,
+		maxPerDay:  maxPerDay,
+		cooldown:   cooldown,
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		r.state.BaseDelaySeconds = baseDelaySeconds
+	case err != nil:
+		return nil, fmt.Errorf("read rate limiter state: %w", err)
+	default:
+		if err := json.Unmarshal(data, &r.state); err != nil {
+			return nil, fmt.Errorf("parse rate limiter state: %w", err)
+		}
+		if r.state.BaseDelaySeconds == 0 {
+			r.state.BaseDelaySeconds = baseDelaySeconds
+		}
+	}
+
+	return r, nil
+}
+
+// save persists the current state. Callers must hold r.mu.
+func (r *RateLimiter) save() error {
+	data, err := json.MarshalIndent(r.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal rate limiter state: %w", err)
+	}
+	return os.WriteFile(r.path, data, 0600)
+}
+
+// prune drops timestamps older than 24 hours. Callers must hold r.mu.
+func (r *RateLimiter) prune() {
+	cutoff := r.now().Add(-24 * time.Hour).Unix()
+	kept := r.state.Unfollows[:0]
+	for _, ts := range r.state.Unfollows {
+		if ts >= cutoff {
+			kept = append(kept, ts)
+		}
+	}
+	r.state.Unfollows = kept
+}
+
+// countSince returns how many unfollows were recorded since cutoff.
+func (r *RateLimiter) countSince(cutoff time.Time) int {
+	count := 0
+	for _, ts := range r.state.Unfollows {
+		if ts >= cutoff.Unix() {
+			count++
+		}
+	}
+	return count
+}
+
+// Allow reports whether another unfollow may happen right now given the
+// hourly/daily quotas, and if not, how long to wait before trying again.
+func (r *RateLimiter) Allow() (ok bool, wait time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prune()
+	now := r.now()
+
+	if r.maxPerHour > 0 && r.countSince(now.Add(-time.Hour)) >= r.maxPerHour {
+		return false, time.Hour
+	}
+	if r.maxPerDay > 0 && r.countSince(now.Add(-24*time.Hour)) >= r.maxPerDay {
+		return false, 24 * time.Hour
+	}
+	return true, 0
+}
+
+// Delay returns the current base per-unfollow delay.
+func (r *RateLimiter) Delay() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.Duration(r.state.BaseDelaySeconds) * time.Second
+}
+
+// RecordSuccess records a successful unfollow at the current time and
+// persists the updated state.
+func (r *RateLimiter) RecordSuccess() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.Unfollows = append(r.state.Unfollows, r.now().Unix())
+	r.prune()
+	return r.save()
+}
+
+// RegisterBlock doubles the base delay after Instagram signals it's
+// blocking the account's actions (e.g. an Action Blocked dialog), persists
+// the new pacing, and returns how long to cool down before resuming.
+func (r *RateLimiter) RegisterBlock() (time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.BaseDelaySeconds *= 2
+	if err := r.save(); err != nil {
+		return r.cooldown, err
+	}
+	return r.cooldown, nil
+}
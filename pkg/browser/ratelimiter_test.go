@@ -0,0 +1,109 @@
+package browser
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterQuotasAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+
+	limiter, err := NewRateLimiter(path, 30, 2, 3, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("NewRateLimiter: %v", err)
+	}
+
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return clock }
+
+	if ok, _ := limiter.Allow(); !ok {
+		t.Fatalf("Allow() = false before any unfollows, want true")
+	}
+
+	if err := limiter.RecordSuccess(); err != nil {
+		t.Fatalf("RecordSuccess: %v", err)
+	}
+	clock = clock.Add(time.Minute)
+	if err := limiter.RecordSuccess(); err != nil {
+		t.Fatalf("RecordSuccess: %v", err)
+	}
+
+	if ok, wait := limiter.Allow(); ok {
+		t.Fatalf("Allow() = true after hitting hourly quota of 2, want false")
+	} else if wait != time.Hour {
+		t.Fatalf("Allow() wait = %v, want 1h", wait)
+	}
+
+	// Reloading from disk should see the persisted unfollow history.
+	reloaded, err := NewRateLimiter(path, 30, 2, 3, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("NewRateLimiter (reload): %v", err)
+	}
+	reloaded.now = func() time.Time { return clock }
+	if ok, _ := reloaded.Allow(); ok {
+		t.Fatalf("Allow() = true after reload, want false (quota should persist across restarts)")
+	}
+}
+
+func TestRateLimiterDailyQuotaAndPruning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+
+	limiter, err := NewRateLimiter(path, 30, 100, 2, time.Minute)
+	if err != nil {
+		t.Fatalf("NewRateLimiter: %v", err)
+	}
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return clock }
+
+	if err := limiter.RecordSuccess(); err != nil {
+		t.Fatalf("RecordSuccess: %v", err)
+	}
+	if err := limiter.RecordSuccess(); err != nil {
+		t.Fatalf("RecordSuccess: %v", err)
+	}
+	if ok, wait := limiter.Allow(); ok {
+		t.Fatalf("Allow() = true after hitting daily quota of 2, want false")
+	} else if wait != 24*time.Hour {
+		t.Fatalf("Allow() wait = %v, want 24h", wait)
+	}
+
+	// Once the old entries age out of the 24h window, the quota frees up.
+	clock = clock.Add(25 * time.Hour)
+	if ok, _ := limiter.Allow(); !ok {
+		t.Fatalf("Allow() = false after entries aged out, want true")
+	}
+}
+
+func TestRateLimiterRegisterBlockDoublesDelay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+
+	limiter, err := NewRateLimiter(path, 30, 0, 0, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("NewRateLimiter: %v", err)
+	}
+
+	if got := limiter.Delay(); got != 30*time.Second {
+		t.Fatalf("Delay() = %v, want 30s", got)
+	}
+
+	cooldown, err := limiter.RegisterBlock()
+	if err != nil {
+		t.Fatalf("RegisterBlock: %v", err)
+	}
+	if cooldown != 5*time.Minute {
+		t.Fatalf("RegisterBlock cooldown = %v, want 5m", cooldown)
+	}
+	if got := limiter.Delay(); got != 60*time.Second {
+		t.Fatalf("Delay() after RegisterBlock = %v, want 60s", got)
+	}
+
+	reloaded, err := NewRateLimiter(path, 30, 0, 0, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("NewRateLimiter (reload): %v", err)
+	}
+	if got := reloaded.Delay(); got != 60*time.Second {
+		t.Fatalf("Delay() after reload = %v, want 60s (backoff should persist)", got)
+	}
+}
@@ -0,0 +1,249 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/domstorage"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// instagramOrigin is the security origin ExportSession/ImportSession read
+// and write DOM storage against.
+const instagramOrigin = "https://www.instagram.com"
+
+// sessionSchemaVersion is bumped whenever sessionExport's shape changes, so
+// ImportSession can reject a blob it no longer knows how to restore instead
+// of silently misinterpreting it.
+const sessionSchemaVersion = 1
+
+// sessionExport is the versioned, portable snapshot produced by
+// Browser.ExportSession. Unlike UserDataDir, it's a plain JSON blob that can
+// be moved between machines or stored in a secrets manager.
+type sessionExport struct {
+	SchemaVersion  int               `json:"schema_version"`
+	ExportedAt     int64             `json:"exported_at"`
+	Cookies        []*network.Cookie `json:"cookies"`
+	LocalStorage   map[string]string `json:"local_storage"`
+	SessionStorage map[string]string `json:"session_storage"`
+	// IndexedDB holds the raw JSON produced by dumpIndexedDBScript, keyed by
+	// database name. It's kept as json.RawMessage so ExportSession/
+	// ImportSession don't need to model Instagram's internal schema.
+	IndexedDB json.RawMessage `json:"indexed_db"`
+}
+
+// dumpIndexedDBScript snapshots every "ig-*" IndexedDB database under the
+// current origin into a plain object keyed by database name. Chrome's
+// IndexedDB CDP domain only hands back entries as opaque RemoteObjects, so
+// it's simpler and more reliable to let the page serialize its own
+// IndexedDB contents and return them by value like any other Evaluate call.
+const dumpIndexedDBScript = `
+(async () => {
+	const result = {};
+	if (!indexedDB.databases) {
+		return result;
+	}
+	const infos = await indexedDB.databases();
+	for (const info of infos) {
+		if (!info.name || !info.name.startsWith('ig-')) {
+			continue;
+		}
+		const db = await new Promise((resolve, reject) => {
+			const req = indexedDB.open(info.name);
+			req.onsuccess = () => resolve(req.result);
+			req.onerror = () => reject(req.error);
+		});
+		const stores = {};
+		for (const storeName of db.objectStoreNames) {
+			stores[storeName] = await new Promise((resolve, reject) => {
+				const tx = db.transaction(storeName, 'readonly');
+				const store = tx.objectStore(storeName);
+				const keysReq = store.getAllKeys();
+				const valuesReq = store.getAll();
+				let keys, values;
+				const maybeResolve = () => {
+					if (keys !== undefined && values !== undefined) {
+						resolve(keys.map((key, i) => ({ key, value: values[i] })));
+					}
+				};
+				keysReq.onsuccess = () => { keys = keysReq.result; maybeResolve(); };
+				valuesReq.onsuccess = () => { values = valuesReq.result; maybeResolve(); };
+				keysReq.onerror = () => reject(keysReq.error);
+				valuesReq.onerror = () => reject(valuesReq.error);
+			});
+		}
+		db.close();
+		result[info.name] = { version: db.version, stores };
+	}
+	return result;
+})()
+`
+
+// restoreIndexedDBScriptTmpl recreates the databases/object stores captured
+// by dumpIndexedDBScript and repopulates them with their saved entries.
+// %s is replaced with sessionExport.IndexedDB, the snapshot's raw JSON.
+const restoreIndexedDBScriptTmpl = `
+(async () => {
+	const snapshot = %s;
+	for (const [dbName, dbData] of Object.entries(snapshot)) {
+		const storeNames = Object.keys(dbData.stores || {});
+		const db = await new Promise((resolve, reject) => {
+			const req = indexedDB.open(dbName, dbData.version || 1);
+			req.onupgradeneeded = () => {
+				const db = req.result;
+				for (const storeName of storeNames) {
+					if (!db.objectStoreNames.contains(storeName)) {
+						db.createObjectStore(storeName);
+					}
+				}
+			};
+			req.onsuccess = () => resolve(req.result);
+			req.onerror = () => reject(req.error);
+		});
+		for (const storeName of storeNames) {
+			await new Promise((resolve, reject) => {
+				const tx = db.transaction(storeName, 'readwrite');
+				const store = tx.objectStore(storeName);
+				for (const entry of dbData.stores[storeName]) {
+					store.put(entry.value, entry.key);
+				}
+				tx.oncomplete = () => resolve();
+				tx.onerror = () => reject(tx.error);
+			});
+		}
+		db.close();
+	}
+})()
+`
+
+// withAwaitPromise tells runtime.Evaluate to wait for the script's returned
+// promise to settle and resolve the result to its fulfilled value, which
+// dumpIndexedDBScript and restoreIndexedDBScriptTmpl both rely on.
+func withAwaitPromise(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+	return p.WithAwaitPromise(true)
+}
+
+// ExportSession captures cookies, localStorage/sessionStorage, and IndexedDB
+// for instagramOrigin as a single versioned JSON blob, so a login performed
+// once via WaitForManualLogin can be bootstrapped onto another machine or
+// container without repeating it.
+func (b *Browser) ExportSession() ([]byte, error) {
+	export := sessionExport{
+		SchemaVersion: sessionSchemaVersion,
+		ExportedAt:    time.Now().Unix(),
+	}
+
+	err := chromedp.Run(b.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		cookies, err := network.GetCookies().Do(ctx)
+		if err != nil {
+			return fmt.Errorf("get cookies: %w", err)
+		}
+		export.Cookies = cookies
+
+		if err := domstorage.Enable().Do(ctx); err != nil {
+			return fmt.Errorf("enable dom storage: %w", err)
+		}
+
+		local, err := domstorage.GetDOMStorageItems(&domstorage.StorageID{
+			SecurityOrigin: instagramOrigin,
+			IsLocalStorage: true,
+		}).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("read localStorage: %w", err)
+		}
+		export.LocalStorage = domStorageItemsToMap(local)
+
+		session, err := domstorage.GetDOMStorageItems(&domstorage.StorageID{
+			SecurityOrigin: instagramOrigin,
+			IsLocalStorage: false,
+		}).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("read sessionStorage: %w", err)
+		}
+		export.SessionStorage = domStorageItemsToMap(session)
+
+		var raw []byte
+		if err := chromedp.Evaluate(dumpIndexedDBScript, &raw, withAwaitPromise).Do(ctx); err != nil {
+			return fmt.Errorf("dump indexeddb: %w", err)
+		}
+		export.IndexedDB = raw
+
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(export)
+}
+
+// ImportSession restores a blob produced by ExportSession. It should be run
+// before navigating to instagram.com, matching how WaitForManualLogin itself
+// navigates only after checking for an existing session.
+func (b *Browser) ImportSession(data []byte) error {
+	var export sessionExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("parse session export: %w", err)
+	}
+	if export.SchemaVersion != sessionSchemaVersion {
+		return fmt.Errorf("unsupported session export schema version %d", export.SchemaVersion)
+	}
+
+	return chromedp.Run(b.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		params := make([]*network.CookieParam, 0, len(export.Cookies))
+		for _, c := range export.Cookies {
+			params = append(params, &network.CookieParam{
+				Name:     c.Name,
+				Value:    c.Value,
+				Domain:   c.Domain,
+				Path:     c.Path,
+				Secure:   c.Secure,
+				HTTPOnly: c.HTTPOnly,
+				SameSite: c.SameSite,
+			})
+		}
+		if err := network.SetCookies(params).Do(ctx); err != nil {
+			return fmt.Errorf("restore cookies: %w", err)
+		}
+
+		if err := domstorage.Enable().Do(ctx); err != nil {
+			return fmt.Errorf("enable dom storage: %w", err)
+		}
+
+		localID := &domstorage.StorageID{SecurityOrigin: instagramOrigin, IsLocalStorage: true}
+		for key, value := range export.LocalStorage {
+			if err := domstorage.SetDOMStorageItem(localID, key, value).Do(ctx); err != nil {
+				return fmt.Errorf("restore localStorage[%s]: %w", key, err)
+			}
+		}
+
+		sessionID := &domstorage.StorageID{SecurityOrigin: instagramOrigin, IsLocalStorage: false}
+		for key, value := range export.SessionStorage {
+			if err := domstorage.SetDOMStorageItem(sessionID, key, value).Do(ctx); err != nil {
+				return fmt.Errorf("restore sessionStorage[%s]: %w", key, err)
+			}
+		}
+
+		if len(export.IndexedDB) == 0 || string(export.IndexedDB) == "null" {
+			return nil
+		}
+		script := fmt.Sprintf(restoreIndexedDBScriptTmpl, string(export.IndexedDB))
+		return chromedp.Evaluate(script, nil, withAwaitPromise).Do(ctx)
+	}))
+}
+
+// domStorageItemsToMap converts DOMStorage's [key, value] pair encoding into
+// a plain map for JSON serialization.
+func domStorageItemsToMap(items []domstorage.Item) map[string]string {
+	m := make(map[string]string, len(items))
+	for _, item := range items {
+		if len(item) == 2 {
+			m[item[0]] = item[1]
+		}
+	}
+	return m
+}
@@ -0,0 +1,112 @@
+// Package drivers defines the interface the rest of the application
+// automates against, so the unfollow pipeline isn't hard-coded to
+// Instagram. Each social network (Instagram, Threads, ...) implements
+// Driver and registers a Factory under its name; application.runUnfollow
+// looks drivers up by the name configured per account.
+package drivers
+
+import (
+	"log/slog"
+
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/browser"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/ratelimit"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
+)
+
+// Result represents the outcome of an Unfollow attempt, generalized across
+// drivers from the Instagram-specific browser.UnfollowResult.
+type Result int
+
+const (
+	ResultSuccess Result = iota
+	// ResultSkipped means the account was already in the desired state
+	// (e.g. not actually following) and shouldn't count against the rate
+	// limit or unfollow delay.
+	ResultSkipped
+	// ResultUnavailable means the profile is gone or unreachable.
+	ResultUnavailable
+	ResultError
+	// ResultRateLimited means the platform itself rejected the request;
+	// the caller should stop the session rather than retry immediately.
+	ResultRateLimited
+	// ResultActionBlocked means the platform has temporarily blocked this
+	// type of action for the account; the caller should stop the session.
+	ResultActionBlocked
+	// ResultChallengeRequired means the platform demanded a challenge
+	// (captcha, suspicious-login confirmation, etc.) that needs a human.
+	ResultChallengeRequired
+)
+
+// Config holds the settings a Driver needs to construct itself. Not every
+// field applies to every driver; browser-automation-based drivers use
+// Browser, Store is shared by all of them to read candidates and persist
+// results.
+type Config struct {
+	Store   storage.Store
+	Browser browser.Config
+}
+
+// Driver is a single social network's automation backend.
+type Driver interface {
+	// Name identifies the driver, matching the value accounts.*.driver
+	// selects in config (e.g. "instagram", "threads").
+	Name() string
+
+	// Login establishes an authenticated session, prompting for manual
+	// login if one isn't already cached.
+	Login() error
+
+	// ListFollowing and ListFollowers return the account's current follow
+	// graph as last imported into Store.
+	ListFollowing() ([]storage.Relationship, error)
+	ListFollowers() ([]storage.Relationship, error)
+
+	Unfollow(username string) (Result, error)
+	Follow(username string) error
+
+	// RateLimits returns this driver's default per-action pacing. The
+	// unfollow pipeline builds its limits from config and does not call
+	// it.
+	RateLimits() ratelimit.ActionLimit
+
+	Close()
+}
+
+// NonFollowerFilter is implemented by drivers that can verify, against a
+// live follower graph rather than a possibly-stale imported snapshot, which
+// of a list of usernames don't follow the logged-in account back. The
+// unfollow CLI consults it when --non-followers-only is set; drivers that
+// don't implement it are simply skipped for that filtering step.
+type NonFollowerFilter interface {
+	FilterNonFollowers(usernames []string) ([]string, error)
+}
+
+// Factory constructs a Driver from Config.
+type Factory func(logger *slog.Logger, cfg Config) (Driver, error)
+
+var registry = map[string]Factory{}
+
+// Register adds a driver factory under name. Drivers call this from an
+// init() so importing the driver package is enough to make it available.
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// New constructs the driver registered under name.
+func New(name string, logger *slog.Logger, cfg Config) (Driver, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, &UnknownDriverError{Name: name}
+	}
+	return factory(logger, cfg)
+}
+
+// UnknownDriverError is returned by New when no driver is registered under
+// the requested name.
+type UnknownDriverError struct {
+	Name string
+}
+
+func (e *UnknownDriverError) Error() string {
+	return "drivers: no driver registered under name " + e.Name
+}
@@ -0,0 +1,109 @@
+// Package instagram implements drivers.Driver on top of pkg/browser's
+// chromedp automation, the driver this application has always shipped.
+package instagram
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/browser"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/drivers"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/ratelimit"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
+)
+
+const Name = "instagram"
+
+func init() {
+	drivers.Register(Name, New)
+}
+
+type Driver struct {
+	logger *slog.Logger
+	store  storage.Store
+	b      *browser.Browser
+}
+
+// New constructs the Instagram driver. It's registered as drivers.Factory
+// under Name, so most callers should go through drivers.New("instagram", ...)
+// instead of calling this directly.
+func New(logger *slog.Logger, cfg drivers.Config) (drivers.Driver, error) {
+	b, err := browser.New(logger, cfg.Browser)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Driver{logger: logger, store: cfg.Store, b: b}, nil
+}
+
+func (d *Driver) Name() string { return Name }
+
+func (d *Driver) Login() error {
+	return d.b.WaitForManualLogin()
+}
+
+// ListFollowing and ListFollowers return the follow graph as last imported
+// from an Instagram data export (see pkg/extraction and cmd/parse.go);
+// Instagram's web UI doesn't expose a stable API for live listing, so
+// importing an export remains the source of truth.
+func (d *Driver) ListFollowing() ([]storage.Relationship, error) {
+	return d.store.AllFollowing()
+}
+
+func (d *Driver) ListFollowers() ([]storage.Relationship, error) {
+	return d.store.AllFollowers()
+}
+
+func (d *Driver) Unfollow(username string) (drivers.Result, error) {
+	result, err := d.b.Unfollow(username)
+	return toDriverResult(result), err
+}
+
+// FilterNonFollowers implements drivers.NonFollowerFilter by checking
+// Instagram's live follower graph, rather than trusting a possibly-stale
+// imported snapshot.
+func (d *Driver) FilterNonFollowers(usernames []string) ([]string, error) {
+	return d.b.FilterNonFollowers(usernames)
+}
+
+// Follow isn't supported: this driver (and the product it ships in) is
+// purpose-built for unfollow automation, and automating follows carries a
+// very different spam/abuse risk profile that's out of scope here.
+func (d *Driver) Follow(username string) error {
+	return errors.New("instagram driver: Follow is not supported")
+}
+
+func (d *Driver) RateLimits() ratelimit.ActionLimit {
+	return ratelimit.ActionLimit{
+		Windows: []ratelimit.Window{
+			{Period: time.Hour, Max: 60},
+			{Period: 24 * time.Hour, Max: 150},
+		},
+		MinDelay: 30 * time.Second,
+		Jitter:   10 * time.Second,
+	}
+}
+
+func (d *Driver) Close() {
+	d.b.Close()
+}
+
+func toDriverResult(r browser.UnfollowResult) drivers.Result {
+	switch r {
+	case browser.UnfollowSuccess:
+		return drivers.ResultSuccess
+	case browser.UnfollowNotFollowing, browser.UnfollowSkipped:
+		return drivers.ResultSkipped
+	case browser.UnfollowProfileUnavailable:
+		return drivers.ResultUnavailable
+	case browser.UnfollowRateLimited:
+		return drivers.ResultRateLimited
+	case browser.UnfollowActionBlocked:
+		return drivers.ResultActionBlocked
+	case browser.UnfollowChallengeRequired:
+		return drivers.ResultChallengeRequired
+	default:
+		return drivers.ResultError
+	}
+}
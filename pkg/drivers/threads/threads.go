@@ -0,0 +1,157 @@
+// Package threads implements drivers.Driver for Threads (threads.net),
+// Meta's companion network to Instagram. It's a lighter-weight chromedp
+// driver than pkg/browser's Instagram implementation: it doesn't carry
+// Instagram's anti-fingerprinting hardening or its locale table, since
+// Threads is a smaller surface and this is its first driver. Extend it the
+// way pkg/browser grew, if Threads automation needs to get more robust.
+package threads
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/chromedp/chromedp"
+
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/drivers"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/ratelimit"
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
+)
+
+const Name = "threads"
+
+func init() {
+	drivers.Register(Name, New)
+}
+
+type Driver struct {
+	logger      *slog.Logger
+	store       storage.Store
+	ctx         context.Context
+	cancel      context.CancelFunc
+	allocCancel context.CancelFunc
+}
+
+// New constructs the Threads driver. It's registered as drivers.Factory
+// under Name, so most callers should go through drivers.New("threads", ...)
+// instead of calling this directly.
+func New(logger *slog.Logger, cfg drivers.Config) (drivers.Driver, error) {
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", cfg.Browser.Headless),
+	)
+	if cfg.Browser.UserDataDir != "" {
+		opts = append(opts, chromedp.UserDataDir(cfg.Browser.UserDataDir))
+	}
+
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), opts...)
+	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(logger.Info))
+
+	return &Driver{logger: logger, store: cfg.Store, ctx: ctx, cancel: cancel, allocCancel: allocCancel}, nil
+}
+
+func (d *Driver) Name() string { return Name }
+
+// Login waits for the user to complete login manually in the visible
+// browser window, mirroring pkg/browser's WaitForManualLogin - Threads logs
+// in through the same Meta accounts flow, so there's no credential storage
+// here either.
+func (d *Driver) Login() error {
+	var loggedIn bool
+	err := chromedp.Run(d.ctx,
+		chromedp.Navigate("https://www.threads.net/"),
+		chromedp.Sleep(2*time.Second),
+		chromedp.Evaluate(`!document.body.innerText.includes('Log in')`, &loggedIn),
+	)
+	if err != nil {
+		return fmt.Errorf("navigate to threads: %w", err)
+	}
+	if loggedIn {
+		return nil
+	}
+
+	d.logger.Info("Please log in to Threads in the opened browser window...")
+	return chromedp.Run(d.ctx,
+		chromedp.WaitVisible(`//a[contains(@href, '/following')]`, chromedp.BySearch),
+	)
+}
+
+// ListFollowing and ListFollowers return the follow graph as last imported
+// into Store; like Instagram, Threads doesn't expose a stable listing API,
+// so an export/import step remains the source of truth.
+func (d *Driver) ListFollowing() ([]storage.Relationship, error) {
+	return d.store.AllFollowing()
+}
+
+func (d *Driver) ListFollowers() ([]storage.Relationship, error) {
+	return d.store.AllFollowers()
+}
+
+func (d *Driver) Unfollow(username string) (drivers.Result, error) {
+	profileURL := fmt.Sprintf("https://www.threads.net/@%s", username)
+
+	err := chromedp.Run(d.ctx,
+		chromedp.Navigate(profileURL),
+		chromedp.Sleep(time.Duration(1500+rand.Intn(1500))*time.Millisecond),
+	)
+	if err != nil {
+		return drivers.ResultError, fmt.Errorf("navigate to profile: %w", err)
+	}
+
+	var status string
+	err = chromedp.Run(d.ctx, chromedp.Evaluate(`
+		(function() {
+			const text = document.body.innerText || '';
+			if (text.includes("Sorry, this page isn't available")) return "unavailable";
+			if (/\bFollowing\b/.test(text)) return "following";
+			if (/\bFollow\b/.test(text)) return "not_following";
+			return "unknown";
+		})()
+	`, &status))
+	if err != nil {
+		return drivers.ResultError, fmt.Errorf("check follow status: %w", err)
+	}
+
+	switch status {
+	case "unavailable":
+		return drivers.ResultUnavailable, nil
+	case "not_following":
+		return drivers.ResultSkipped, nil
+	case "unknown":
+		return drivers.ResultError, fmt.Errorf("could not determine follow status for %s", username)
+	}
+
+	err = chromedp.Run(d.ctx,
+		chromedp.Click(`//div[text()='Following']`, chromedp.BySearch),
+		chromedp.Sleep(time.Duration(1000+rand.Intn(1000))*time.Millisecond),
+		chromedp.Click(`//div[text()='Unfollow']`, chromedp.BySearch),
+		chromedp.Sleep(time.Duration(1000+rand.Intn(1000))*time.Millisecond),
+	)
+	if err != nil {
+		return drivers.ResultError, fmt.Errorf("click unfollow: %w", err)
+	}
+
+	return drivers.ResultSuccess, nil
+}
+
+// Follow isn't supported; see instagram.Driver.Follow for why.
+func (d *Driver) Follow(username string) error {
+	return fmt.Errorf("threads driver: Follow is not supported")
+}
+
+func (d *Driver) RateLimits() ratelimit.ActionLimit {
+	return ratelimit.ActionLimit{
+		Windows: []ratelimit.Window{
+			{Period: time.Hour, Max: 60},
+			{Period: 24 * time.Hour, Max: 150},
+		},
+		MinDelay: 30 * time.Second,
+		Jitter:   10 * time.Second,
+	}
+}
+
+func (d *Driver) Close() {
+	d.cancel()
+	d.allocCancel()
+}
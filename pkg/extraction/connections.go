@@ -0,0 +1,111 @@
+package extraction
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
+)
+
+// ConnectionKind identifies one of the non-follower/following relationship
+// lists Instagram includes in a data export.
+type ConnectionKind string
+
+const (
+	KindBlockedProfiles        ConnectionKind = "blocked_profiles"
+	KindCloseFriends           ConnectionKind = "close_friends"
+	KindFollowRequestsReceived ConnectionKind = "follow_requests_received"
+	KindPendingFollowRequests  ConnectionKind = "pending_follow_requests"
+	KindRecentlyUnfollowed     ConnectionKind = "recently_unfollowed"
+	KindRemovedSuggestions     ConnectionKind = "removed_suggestions"
+	KindRestrictedProfiles     ConnectionKind = "restricted_profiles"
+	KindHideStoryFrom          ConnectionKind = "hide_story_from"
+)
+
+// connectionFiles maps each kind to its filename within
+// connections/followers_and_following.
+var connectionFiles = map[ConnectionKind]string{
+	KindBlockedProfiles:        "blocked_profiles.json",
+	KindCloseFriends:           "close_friends.json",
+	KindFollowRequestsReceived: "follow_requests_you've_received.json",
+	KindPendingFollowRequests:  "pending_follow_requests.json",
+	KindRecentlyUnfollowed:     "recently_unfollowed_profiles.json",
+	KindRemovedSuggestions:     "removed_suggestions.json",
+	KindRestrictedProfiles:     "restricted_profiles.json",
+	KindHideStoryFrom:          "hide_story_from.json",
+}
+
+// ConnectionKinds lists every kind parseToDB knows how to ingest.
+func ConnectionKinds() []ConnectionKind {
+	kinds := make([]ConnectionKind, 0, len(connectionFiles))
+	for kind := range connectionFiles {
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// FindConnectionFile returns the path to kind's export file within dir, or
+// "" if the export doesn't include it (not every export has every list).
+func FindConnectionFile(dir string, kind ConnectionKind) (string, error) {
+	name, ok := connectionFiles[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown connection kind %q", kind)
+	}
+
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return path, nil
+}
+
+// ParseConnectionFile parses one of the connection JSON files. Instagram
+// exports these either as a bare array of entries (the same shape as
+// followers_*.json) or as an object with a single "relationships_<kind>"
+// key wrapping that array, depending on export version.
+func ParseConnectionFile(jsonPath string, kind ConnectionKind) ([]storage.Relationship, error) {
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", kind, err)
+	}
+
+	var entries []followerEntry
+	if err := json.Unmarshal(data, &entries); err == nil {
+		return relationshipsFromEntries(entries), nil
+	}
+
+	var wrapped map[string][]followerEntry
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", kind, err)
+	}
+	for _, entries := range wrapped {
+		return relationshipsFromEntries(entries), nil
+	}
+
+	return nil, nil
+}
+
+func relationshipsFromEntries(entries []followerEntry) []storage.Relationship {
+	var out []storage.Relationship
+	for _, entry := range entries {
+		if len(entry.StringListData) == 0 {
+			continue
+		}
+		sld := entry.StringListData[0]
+		username := sld.Value
+		if username == "" {
+			username = entry.Title
+		}
+		out = append(out, storage.Relationship{
+			Username:  username,
+			Href:      sld.Href,
+			Timestamp: sld.Timestamp,
+		})
+	}
+	return out
+}
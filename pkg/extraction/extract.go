@@ -1,7 +1,10 @@
 package extraction
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -9,51 +12,200 @@ import (
 	"strings"
 )
 
-func Unzip(zipFile, destDir string) error {
+// Default limits applied by Unzip and UntarGz when the caller passes a zero
+// UnzipOptions, chosen to comfortably fit a real Instagram data export while
+// still bounding a malicious or corrupt archive.
+const (
+	DefaultMaxFileSize  = 2 << 30 // 2 GiB
+	DefaultMaxTotalSize = 2 << 30 // 2 GiB
+	DefaultMaxFiles     = 100_000
+)
+
+// ErrArchiveTooLarge is returned when an archive's total or per-file
+// uncompressed size exceeds the configured limit.
+var ErrArchiveTooLarge = errors.New("extraction: archive exceeds configured size limit")
+
+// ErrTooManyFiles is returned when an archive contains more entries than
+// MaxFiles allows.
+var ErrTooManyFiles = errors.New("extraction: archive exceeds configured file count limit")
+
+// UnzipOptions bounds the resources Unzip and UntarGz are willing to spend
+// extracting an archive, guarding against decompression bombs. A zero value
+// is replaced with DefaultMaxFileSize/DefaultMaxTotalSize/DefaultMaxFiles.
+type UnzipOptions struct {
+	MaxFileSize  int64
+	MaxTotalSize int64
+	MaxFiles     int
+}
+
+func (o UnzipOptions) withDefaults() UnzipOptions {
+	if o.MaxFileSize <= 0 {
+		o.MaxFileSize = DefaultMaxFileSize
+	}
+	if o.MaxTotalSize <= 0 {
+		o.MaxTotalSize = DefaultMaxTotalSize
+	}
+	if o.MaxFiles <= 0 {
+		o.MaxFiles = DefaultMaxFiles
+	}
+	return o
+}
+
+// Unzip extracts zipFile into destDir, enforcing opts (or the defaults, if
+// opts is the zero value) against decompression bombs.
+func Unzip(zipFile, destDir string, opts UnzipOptions) error {
 	reader, err := zip.OpenReader(zipFile)
 	if err != nil {
 		return err
 	}
 	defer reader.Close()
 
+	opts = opts.withDefaults()
 	destDir = filepath.Clean(destDir)
 
+	if len(reader.File) > opts.MaxFiles {
+		return fmt.Errorf("%w: %d entries exceeds limit of %d", ErrTooManyFiles, len(reader.File), opts.MaxFiles)
+	}
+
+	var total int64
 	for _, file := range reader.File {
-		if err := extractFile(file, destDir); err != nil {
+		n, err := extractZipFile(file, destDir, opts)
+		if err != nil {
 			return err
 		}
+		total += n
+		if total > opts.MaxTotalSize {
+			return fmt.Errorf("%w: extracted %d bytes exceeds limit of %d", ErrArchiveTooLarge, total, opts.MaxTotalSize)
+		}
 	}
 	return nil
 }
 
-func extractFile(file *zip.File, destDir string) error {
+func extractZipFile(file *zip.File, destDir string, opts UnzipOptions) (int64, error) {
 	path := filepath.Join(destDir, file.Name)
 
 	// Guard against ZipSlip
 	if !strings.HasPrefix(path, filepath.Clean(destDir)+string(os.PathSeparator)) {
-		return fmt.Errorf("illegal file path: %s", path)
+		return 0, fmt.Errorf("illegal file path: %s", path)
 	}
 
 	if file.FileInfo().IsDir() {
-		return os.MkdirAll(path, os.ModePerm)
+		return 0, os.MkdirAll(path, os.ModePerm)
+	}
+
+	if int64(file.UncompressedSize64) > opts.MaxFileSize {
+		return 0, fmt.Errorf("%w: %s is %d bytes, exceeds limit of %d", ErrArchiveTooLarge, file.Name, file.UncompressedSize64, opts.MaxFileSize)
 	}
 
 	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
-		return err
+		return 0, err
 	}
 
 	dstFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.Mode())
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer dstFile.Close()
 
 	srcFile, err := file.Open()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer srcFile.Close()
 
-	_, err = io.Copy(dstFile, srcFile)
-	return err
+	n, err := io.Copy(dstFile, io.LimitReader(srcFile, opts.MaxFileSize+1))
+	if err != nil {
+		return n, err
+	}
+	if n > opts.MaxFileSize {
+		return n, fmt.Errorf("%w: %s exceeds limit of %d", ErrArchiveTooLarge, file.Name, opts.MaxFileSize)
+	}
+	return n, nil
+}
+
+// UntarGz extracts a gzip-compressed tar archive into destDir, enforcing
+// opts (or the defaults, if opts is the zero value) against decompression
+// bombs, for users who repackage their Instagram export as a .tar.gz.
+func UntarGz(tarGzFile, destDir string, opts UnzipOptions) error {
+	f, err := os.Open(tarGzFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gzr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+
+	opts = opts.withDefaults()
+	destDir = filepath.Clean(destDir)
+	tr := tar.NewReader(gzr)
+
+	var total int64
+	var count int
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		count++
+		if count > opts.MaxFiles {
+			return fmt.Errorf("%w: more than %d entries", ErrTooManyFiles, opts.MaxFiles)
+		}
+
+		n, err := extractTarEntry(tr, hdr, destDir, opts)
+		if err != nil {
+			return err
+		}
+		total += n
+		if total > opts.MaxTotalSize {
+			return fmt.Errorf("%w: extracted %d bytes exceeds limit of %d", ErrArchiveTooLarge, total, opts.MaxTotalSize)
+		}
+	}
+	return nil
+}
+
+func extractTarEntry(tr *tar.Reader, hdr *tar.Header, destDir string, opts UnzipOptions) (int64, error) {
+	path := filepath.Join(destDir, hdr.Name)
+
+	// Guard against the tar equivalent of ZipSlip
+	if !strings.HasPrefix(path, filepath.Clean(destDir)+string(os.PathSeparator)) {
+		return 0, fmt.Errorf("illegal file path: %s", path)
+	}
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return 0, os.MkdirAll(path, os.ModePerm)
+	case tar.TypeReg:
+		if hdr.Size > opts.MaxFileSize {
+			return 0, fmt.Errorf("%w: %s is %d bytes, exceeds limit of %d", ErrArchiveTooLarge, hdr.Name, hdr.Size, opts.MaxFileSize)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return 0, err
+		}
+
+		dstFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(hdr.Mode))
+		if err != nil {
+			return 0, err
+		}
+		defer dstFile.Close()
+
+		n, err := io.Copy(dstFile, io.LimitReader(tr, opts.MaxFileSize+1))
+		if err != nil {
+			return n, err
+		}
+		if n > opts.MaxFileSize {
+			return n, fmt.Errorf("%w: %s exceeds limit of %d", ErrArchiveTooLarge, hdr.Name, opts.MaxFileSize)
+		}
+		return n, nil
+	default:
+		return 0, nil
+	}
 }
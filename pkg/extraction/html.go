@@ -0,0 +1,93 @@
+package extraction
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
+)
+
+// profileHref is the prefix Instagram's HTML export uses for profile links,
+// e.g. "https://www.instagram.com/someuser".
+const profileHref = "https://www.instagram.com/"
+
+// ParseFollowingHTML parses a following.html file from Instagram's HTML
+// data export.
+func ParseFollowingHTML(htmlPath string) ([]storage.Relationship, error) {
+	rels, err := parseRelationshipsHTML(htmlPath)
+	if err != nil {
+		return nil, fmt.Errorf("parse following.html: %w", err)
+	}
+	return rels, nil
+}
+
+// ParseFollowersHTML parses a followers_*.html file from Instagram's HTML
+// data export.
+func ParseFollowersHTML(htmlPath string) ([]storage.Relationship, error) {
+	rels, err := parseRelationshipsHTML(htmlPath)
+	if err != nil {
+		return nil, fmt.Errorf("parse followers html: %w", err)
+	}
+	return rels, nil
+}
+
+// parseRelationshipsHTML walks htmlPath for anchors of the form
+// <a href="https://www.instagram.com/USER">USER</a>, which is how
+// Instagram's HTML export lists every relationships section (following,
+// followers, close friends, etc). The export doesn't associate a
+// machine-readable timestamp with each entry, so Timestamp is left zero.
+func parseRelationshipsHTML(htmlPath string) ([]storage.Relationship, error) {
+	f, err := os.Open(htmlPath)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", htmlPath, err)
+	}
+	defer f.Close()
+
+	var out []storage.Relationship
+	z := html.NewTokenizer(f)
+	var pendingHref string
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, fmt.Errorf("tokenize %s: %w", htmlPath, err)
+			}
+			return out, nil
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "a" || !hasAttr {
+				continue
+			}
+			for {
+				key, val, more := z.TagAttr()
+				if string(key) == "href" && strings.HasPrefix(string(val), profileHref) {
+					pendingHref = string(val)
+				}
+				if !more {
+					break
+				}
+			}
+
+		case html.TextToken:
+			if pendingHref == "" {
+				continue
+			}
+			username := strings.TrimSpace(string(z.Text()))
+			if username == "" {
+				continue
+			}
+			out = append(out, storage.Relationship{
+				Username: username,
+				Href:     pendingHref,
+			})
+			pendingHref = ""
+		}
+	}
+}
@@ -91,17 +91,113 @@ func ParseFollowers(jsonPath string) ([]storage.Relationship, error) {
 	return out, nil
 }
 
-// FindFollowerFiles finds all followers_*.json files in the directory
-func FindFollowerFiles(dir string) ([]string, error) {
-	var files []string
+// Format identifies which variant of Instagram's data export a connections
+// directory uses.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatHTML Format = "html"
+)
+
+// DetectFormat inspects dir (a connections/followers_and_following
+// directory) for a following.json or following.html file and returns
+// which export format it's in.
+func DetectFormat(dir string) (Format, error) {
+	if _, err := os.Stat(filepath.Join(dir, "following.json")); err == nil {
+		return FormatJSON, nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, "following.html")); err == nil {
+		return FormatHTML, nil
+	}
+	return "", fmt.Errorf("could not detect export format in %s: no following.json or following.html", dir)
+}
+
+// RelationshipFiles is the set of files FindRelationshipFiles locates for
+// one export format: a single following file and one or more followers
+// files (Instagram can split followers across several numbered files).
+type RelationshipFiles struct {
+	Following string
+	Followers []string
+}
+
+// FindRelationshipFiles locates the following and followers_* files in dir
+// for format. It generalizes the old FindFollowerFiles (followers_*.json
+// only) to cover both the JSON and HTML export variants.
+func FindRelationshipFiles(dir string, format Format) (RelationshipFiles, error) {
+	var ext string
+	switch format {
+	case FormatJSON:
+		ext = ".json"
+	case FormatHTML:
+		ext = ".html"
+	default:
+		return RelationshipFiles{}, fmt.Errorf("unknown export format %q", format)
+	}
+
+	var files RelationshipFiles
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if !info.IsDir() && strings.HasPrefix(info.Name(), "followers") && strings.HasSuffix(info.Name(), ".json") {
-			files = append(files, path)
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ext) {
+			return nil
+		}
+		switch {
+		case info.Name() == "following"+ext:
+			files.Following = path
+		case strings.HasPrefix(info.Name(), "followers"):
+			files.Followers = append(files.Followers, path)
 		}
 		return nil
 	})
 	return files, err
 }
+
+// FindFollowerFiles finds all followers_*.json files in the directory.
+//
+// Deprecated: use FindRelationshipFiles(dir, FormatJSON).Followers instead.
+func FindFollowerFiles(dir string) ([]string, error) {
+	files, err := FindRelationshipFiles(dir, FormatJSON)
+	if err != nil {
+		return nil, err
+	}
+	return files.Followers, nil
+}
+
+// Parse auto-detects whether dir (a connections/followers_and_following
+// directory) holds the JSON or HTML export variant and parses its
+// following and followers relationships accordingly.
+func Parse(dir string) (following, followers []storage.Relationship, err error) {
+	format, err := DetectFormat(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := FindRelationshipFiles(dir, format)
+	if err != nil {
+		return nil, nil, fmt.Errorf("find relationship files: %w", err)
+	}
+
+	parseFollowing, parseFollowers := ParseFollowing, ParseFollowers
+	if format == FormatHTML {
+		parseFollowing, parseFollowers = ParseFollowingHTML, ParseFollowersHTML
+	}
+
+	if files.Following != "" {
+		following, err = parseFollowing(files.Following)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse following: %w", err)
+		}
+	}
+
+	for _, f := range files.Followers {
+		rels, err := parseFollowers(f)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse %s: %w", f, err)
+		}
+		followers = append(followers, rels...)
+	}
+
+	return following, followers, nil
+}
@@ -1,18 +1,29 @@
+// Package power prevents the system from sleeping while the unfollow
+// automation is running, so a long run doesn't get interrupted by the
+// display or the machine going idle.
 package power
 
 import (
-	"context"
 	"log/slog"
-	"os/exec"
-	"runtime"
 )
 
-// Inhibitor prevents the system from going to sleep
+// inhibitHandle is whatever a platform's startInhibit needs to hand back to
+// its stopInhibit to release the lock (a D-Bus file descriptor on Linux, an
+// IOPMAssertionID on macOS with cgo, the caffeinate child process on macOS
+// without cgo, nothing on Windows/other).
+type inhibitHandle any
+
+// Inhibitor prevents the system from going to sleep. Start/Stop delegate to
+// a native, in-process implementation per OS (see power_linux.go,
+// power_windows.go, power_darwin_cgo.go, power_other.go) rather than
+// spawning a helper process, so there's nothing left running if the
+// application crashes and it works headlessly in containers/services. The
+// one exception is a macOS build without cgo (power_darwin_nocgo.go), which
+// runs caffeinate as a child process.
 type Inhibitor struct {
 	logger  *slog.Logger
-	cancel  context.CancelFunc
-	cmd     *exec.Cmd
 	running bool
+	handle  inhibitHandle
 }
 
 // NewInhibitor creates a new sleep inhibitor
@@ -28,17 +39,14 @@ func (i *Inhibitor) Start() error {
 		return nil
 	}
 
-	switch runtime.GOOS {
-	case "darwin":
-		return i.startMacOS()
-	case "windows":
-		return i.startWindows()
-	case "linux":
-		return i.startLinux()
-	default:
-		i.logger.Warn("Sleep inhibition not supported on this OS", slog.String("os", runtime.GOOS))
-		return nil
+	handle, err := startInhibit(i.logger)
+	if err != nil {
+		return err
 	}
+
+	i.handle = handle
+	i.running = true
+	return nil
 }
 
 // Stop allows the system to sleep again
@@ -47,96 +55,9 @@ func (i *Inhibitor) Stop() {
 		return
 	}
 
-	if i.cancel != nil {
-		i.cancel()
-	}
-
-	if i.cmd != nil && i.cmd.Process != nil {
-		i.cmd.Process.Kill()
-		i.cmd.Wait()
-	}
+	stopInhibit(i.logger, i.handle)
 
+	i.handle = nil
 	i.running = false
 	i.logger.Info("Sleep inhibition stopped")
 }
-
-// startMacOS uses caffeinate to prevent sleep
-func (i *Inhibitor) startMacOS() error {
-	// caffeinate -i: prevent idle sleep
-	// caffeinate -d: prevent display sleep
-	ctx, cancel := context.WithCancel(context.Background())
-	i.cancel = cancel
-
-	i.cmd = exec.CommandContext(ctx, "caffeinate", "-i", "-d")
-	if err := i.cmd.Start(); err != nil {
-		cancel()
-		return err
-	}
-
-	i.running = true
-	i.logger.Info("Sleep inhibition started", slog.String("os", "macOS"), slog.String("method", "caffeinate"))
-	return nil
-}
-
-// startWindows uses PowerShell to prevent sleep
-func (i *Inhibitor) startWindows() error {
-	ctx, cancel := context.WithCancel(context.Background())
-	i.cancel = cancel
-
-	// PowerShell script that keeps running and prevents sleep
-	script := `
-Add-Type -TypeDefinition @"
-using System;
-using System.Runtime.InteropServices;
-public class PowerState {
-    [DllImport("kernel32.dll")]
-    public static extern uint SetThreadExecutionState(uint esFlags);
-}
-"@
-[PowerState]::SetThreadExecutionState(0x80000003)
-while ($true) {
-    Start-Sleep -Seconds 30
-    [PowerState]::SetThreadExecutionState(0x80000003)
-}
-`
-
-	i.cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script)
-	if err := i.cmd.Start(); err != nil {
-		cancel()
-		return err
-	}
-
-	i.running = true
-	i.logger.Info("Sleep inhibition started", slog.String("os", "windows"), slog.String("method", "SetThreadExecutionState"))
-	return nil
-}
-
-// startLinux tries multiple methods to prevent sleep
-func (i *Inhibitor) startLinux() error {
-	ctx, cancel := context.WithCancel(context.Background())
-	i.cancel = cancel
-
-	// Try systemd-inhibit first (most common on modern Linux)
-	if path, err := exec.LookPath("systemd-inhibit"); err == nil {
-		i.cmd = exec.CommandContext(ctx, path, "--what=idle:sleep", "--who=instagram-unfollow", "--why=Unfollow automation running", "sleep", "infinity")
-		if err := i.cmd.Start(); err == nil {
-			i.running = true
-			i.logger.Info("Sleep inhibition started", slog.String("os", "linux"), slog.String("method", "systemd-inhibit"))
-			return nil
-		}
-	}
-
-	// Try gnome-session-inhibit
-	if path, err := exec.LookPath("gnome-session-inhibit"); err == nil {
-		i.cmd = exec.CommandContext(ctx, path, "--inhibit=idle:suspend", "--reason=Unfollow automation running", "sleep", "infinity")
-		if err := i.cmd.Start(); err == nil {
-			i.running = true
-			i.logger.Info("Sleep inhibition started", slog.String("os", "linux"), slog.String("method", "gnome-session-inhibit"))
-			return nil
-		}
-	}
-
-	cancel()
-	i.logger.Warn("No sleep inhibition method available on this Linux system")
-	return nil
-}
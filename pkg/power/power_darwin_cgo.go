@@ -0,0 +1,43 @@
+//go:build darwin && cgo
+
+package power
+
+/*
+#cgo LDFLAGS: -framework IOKit -framework CoreFoundation
+#include <IOKit/pwr_mgt/IOPMLib.h>
+#include <CoreFoundation/CoreFoundation.h>
+
+static IOPMAssertionID createAssertion(const char *reason) {
+	IOPMAssertionID id = kIOPMNullAssertionID;
+	CFStringRef reasonRef = CFStringCreateWithCString(kCFAllocatorDefault, reason, kCFStringEncodingUTF8);
+	IOPMAssertionCreateWithName(kIOPMAssertionTypePreventUserIdleSystemSleep, kIOPMAssertionLevelOn, reasonRef, &id);
+	CFRelease(reasonRef);
+	return id;
+}
+*/
+import "C"
+
+import (
+	"log/slog"
+)
+
+// startInhibit creates an IOKit power assertion directly through cgo
+// instead of spawning caffeinate as a child process.
+func startInhibit(logger *slog.Logger) (inhibitHandle, error) {
+	id := C.createAssertion(C.CString("Unfollow automation running"))
+	if id == C.kIOPMNullAssertionID {
+		logger.Warn("Sleep inhibition unavailable: IOPMAssertionCreateWithName failed")
+		return nil, nil
+	}
+
+	logger.Info("Sleep inhibition started", slog.String("os", "macOS"), slog.String("method", "IOPMAssertionCreateWithName"))
+	return C.IOPMAssertionID(id), nil
+}
+
+func stopInhibit(logger *slog.Logger, handle inhibitHandle) {
+	id, ok := handle.(C.IOPMAssertionID)
+	if !ok || id == C.kIOPMNullAssertionID {
+		return
+	}
+	C.IOPMAssertionRelease(id)
+}
@@ -0,0 +1,42 @@
+//go:build darwin && !cgo
+
+package power
+
+import (
+	"context"
+	"log/slog"
+	"os/exec"
+)
+
+// startInhibit falls back to spawning caffeinate when cgo is disabled
+// (CGO_ENABLED=0), since IOKit's IOPMAssertionCreateWithName isn't
+// reachable without cgo.
+func startInhibit(logger *slog.Logger) (inhibitHandle, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cmd := exec.CommandContext(ctx, "caffeinate", "-i", "-d")
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	logger.Info("Sleep inhibition started", slog.String("os", "macOS"), slog.String("method", "caffeinate (cgo disabled)"))
+	return &darwinFallback{cancel: cancel, cmd: cmd}, nil
+}
+
+func stopInhibit(logger *slog.Logger, handle inhibitHandle) {
+	h, ok := handle.(*darwinFallback)
+	if !ok || h == nil {
+		return
+	}
+	h.cancel()
+	if h.cmd.Process != nil {
+		h.cmd.Process.Kill()
+		h.cmd.Wait()
+	}
+}
+
+type darwinFallback struct {
+	cancel context.CancelFunc
+	cmd    *exec.Cmd
+}
@@ -0,0 +1,61 @@
+//go:build linux
+
+package power
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// startInhibit acquires a logind sleep/idle inhibitor lock over D-Bus and
+// holds the returned file descriptor open for the lifetime of the
+// automation; closing it (in stopInhibit) releases the lock. This avoids
+// spawning `systemd-inhibit sleep infinity` as a child process.
+func startInhibit(logger *slog.Logger) (inhibitHandle, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		logger.Warn("Sleep inhibition unavailable: could not connect to system D-Bus", slog.Any("error", err))
+		return nil, nil
+	}
+
+	obj := conn.Object("org.freedesktop.login1", dbus.ObjectPath("/org/freedesktop/login1"))
+
+	var fd dbus.UnixFD
+	call := obj.Call("org.freedesktop.login1.Manager.Inhibit", 0,
+		"idle:sleep",
+		"instagram-unfollow",
+		"Unfollow automation running",
+		"block",
+	)
+	if call.Err != nil {
+		conn.Close()
+		logger.Warn("Sleep inhibition unavailable: Inhibit call failed", slog.Any("error", call.Err))
+		return nil, nil
+	}
+	if err := call.Store(&fd); err != nil {
+		conn.Close()
+		logger.Warn("Sleep inhibition unavailable: could not read inhibitor fd", slog.Any("error", err))
+		return nil, nil
+	}
+
+	logger.Info("Sleep inhibition started", slog.String("os", "linux"), slog.String("method", "logind Inhibit"))
+	return &linuxInhibit{conn: conn, fd: os.NewFile(uintptr(fd), "inhibitor")}, nil
+}
+
+func stopInhibit(logger *slog.Logger, handle inhibitHandle) {
+	h, ok := handle.(*linuxInhibit)
+	if !ok || h == nil {
+		return
+	}
+	if h.fd != nil {
+		h.fd.Close()
+	}
+	h.conn.Close()
+}
+
+type linuxInhibit struct {
+	conn *dbus.Conn
+	fd   *os.File
+}
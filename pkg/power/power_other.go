@@ -0,0 +1,16 @@
+//go:build !linux && !windows && !darwin
+
+package power
+
+import (
+	"log/slog"
+	"runtime"
+)
+
+// startInhibit is a no-op on platforms with no known inhibition mechanism.
+func startInhibit(logger *slog.Logger) (inhibitHandle, error) {
+	logger.Warn("Sleep inhibition not supported on this OS", slog.String("os", runtime.GOOS))
+	return nil, nil
+}
+
+func stopInhibit(logger *slog.Logger, handle inhibitHandle) {}
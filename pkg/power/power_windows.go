@@ -0,0 +1,35 @@
+//go:build windows
+
+package power
+
+import (
+	"log/slog"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	esContinuous      = 0x80000000
+	esSystemRequired  = 0x00000001
+	esDisplayRequired = 0x00000002
+)
+
+var kernel32 = windows.NewLazySystemDLL("kernel32.dll")
+var procSetThreadExecutionState = kernel32.NewProc("SetThreadExecutionState")
+
+// startInhibit calls SetThreadExecutionState directly instead of spawning a
+// PowerShell helper process, so there's no child process to keep alive (or
+// leak) and no dependence on PowerShell's execution policy.
+func startInhibit(logger *slog.Logger) (inhibitHandle, error) {
+	setThreadExecutionState(esContinuous | esSystemRequired | esDisplayRequired)
+	logger.Info("Sleep inhibition started", slog.String("os", "windows"), slog.String("method", "SetThreadExecutionState"))
+	return nil, nil
+}
+
+func stopInhibit(logger *slog.Logger, handle inhibitHandle) {
+	setThreadExecutionState(esContinuous)
+}
+
+func setThreadExecutionState(flags uint32) {
+	procSetThreadExecutionState.Call(uintptr(flags))
+}
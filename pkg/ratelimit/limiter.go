@@ -0,0 +1,181 @@
+// Package ratelimit paces automated actions (unfollow, follow, like,
+// comment, ...) against a shared set of per-action windows, backed by
+// storage.Store's session_actions table.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
+)
+
+// Window caps how many of a given action may happen within Period.
+type Window struct {
+	Period time.Duration
+	Max    int
+}
+
+// ActionLimit configures the windows and pacing for one action type.
+type ActionLimit struct {
+	// Windows are checked in order; the first one at capacity determines
+	// the wait Reserve returns. A nil/empty Windows leaves the action
+	// unthrottled by count, subject only to MinDelay.
+	Windows []Window
+	// MinDelay is the minimum time Reserve requires since the action was
+	// last recorded, regardless of window capacity.
+	MinDelay time.Duration
+	// Jitter, if set, adds a random extra delay in [0, Jitter) on top of
+	// MinDelay, so consecutive actions don't land on a fixed cadence.
+	Jitter time.Duration
+}
+
+// Stats is a point-in-time snapshot of an action's usage, returned by
+// Snapshot for logging or display.
+type Stats struct {
+	Action         string
+	WindowCounts   map[time.Duration]int
+	OldestInWindow map[time.Duration]time.Time
+}
+
+// Limiter paces actions across one or more windows per action type,
+// persisting usage via a storage.Store so limits survive process restarts
+// and are shared by anything using the same store.
+type Limiter struct {
+	store  storage.Store
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	limits map[string]ActionLimit
+}
+
+// NewLimiter returns a Limiter that enforces limits (keyed by action, e.g.
+// "unfollow", "follow", "like", "comment") against store.
+func NewLimiter(store storage.Store, logger *slog.Logger, limits map[string]ActionLimit) *Limiter {
+	return &Limiter{store: store, logger: logger, limits: limits}
+}
+
+// SetLimits atomically replaces the limits Reserve/Snapshot enforce, so a
+// config hot-reload takes effect at the next call without restarting
+// whatever's using the Limiter.
+func (l *Limiter) SetLimits(limits map[string]ActionLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits = limits
+}
+
+func (l *Limiter) limit(action string) (ActionLimit, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	limit, ok := l.limits[action]
+	return limit, ok
+}
+
+// Reserve reports how long the caller should wait before performing action.
+// A zero wait means it's clear to proceed now. Actions with no configured
+// ActionLimit are never throttled.
+func (l *Limiter) Reserve(ctx context.Context, action string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	limit, ok := l.limit(action)
+	if !ok {
+		return 0, nil
+	}
+
+	for _, w := range limit.Windows {
+		if w.Max <= 0 {
+			continue
+		}
+		count, err := l.store.ActionsInWindow(action, w.Period)
+		if err != nil {
+			return 0, fmt.Errorf("count actions in window: %w", err)
+		}
+		if count < w.Max {
+			continue
+		}
+
+		oldest, err := l.store.OldestActionInWindow(action, w.Period)
+		if err != nil {
+			return 0, fmt.Errorf("find oldest action in window: %w", err)
+		}
+		wait := time.Until(time.Unix(oldest, 0).Add(w.Period))
+		if wait < 0 {
+			wait = 0
+		}
+		l.logger.Warn("Rate limit reached, throttling",
+			slog.String("action", action),
+			slog.Duration("window", w.Period),
+			slog.Int("max", w.Max),
+			slog.Duration("wait", wait),
+		)
+		return wait, nil
+	}
+
+	if limit.MinDelay > 0 {
+		newest, err := l.store.NewestActionInWindow(action, limit.MinDelay+limit.Jitter)
+		if err != nil {
+			return 0, fmt.Errorf("find newest action: %w", err)
+		}
+		if newest > 0 {
+			minDelay := limit.MinDelay
+			if limit.Jitter > 0 {
+				minDelay += time.Duration(rand.Int63n(int64(limit.Jitter)))
+			}
+			elapsed := time.Since(time.Unix(newest, 0))
+			if elapsed < minDelay {
+				wait := minDelay - elapsed
+				l.logger.Info("Minimum inter-action delay not yet elapsed",
+					slog.String("action", action),
+					slog.Duration("wait", wait),
+				)
+				return wait, nil
+			}
+		}
+	}
+
+	return 0, nil
+}
+
+// Record persists that action was just performed against username.
+func (l *Limiter) Record(action, username string) error {
+	return l.store.RecordAction(action, username)
+}
+
+// Snapshot returns current usage counts for action across its configured
+// windows, for logging or display.
+func (l *Limiter) Snapshot(action string) (Stats, error) {
+	stats := Stats{
+		Action:         action,
+		WindowCounts:   map[time.Duration]int{},
+		OldestInWindow: map[time.Duration]time.Time{},
+	}
+
+	limit, ok := l.limit(action)
+	if !ok {
+		return stats, nil
+	}
+
+	for _, w := range limit.Windows {
+		count, err := l.store.ActionsInWindow(action, w.Period)
+		if err != nil {
+			return stats, fmt.Errorf("count actions in window: %w", err)
+		}
+		stats.WindowCounts[w.Period] = count
+
+		oldest, err := l.store.OldestActionInWindow(action, w.Period)
+		if err != nil {
+			return stats, fmt.Errorf("find oldest action in window: %w", err)
+		}
+		if oldest > 0 {
+			stats.OldestInWindow[w.Period] = time.Unix(oldest, 0)
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
+)
+
+func TestLimiterReserveHourlyWindow(t *testing.T) {
+	store := storage.NewMemoryStore()
+	defer store.Close()
+
+	limiter := NewLimiter(store, slog.New(slog.DiscardHandler), map[string]ActionLimit{
+		"unfollow": {Windows: []Window{{Period: time.Hour, Max: 2}}},
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		wait, err := limiter.Reserve(ctx, "unfollow")
+		if err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+		if wait != 0 {
+			t.Fatalf("Reserve() wait = %v before hitting cap, want 0", wait)
+		}
+		if err := limiter.Record("unfollow", "user"); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	wait, err := limiter.Reserve(ctx, "unfollow")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if wait <= 0 {
+		t.Fatalf("Reserve() wait = %v after hitting hourly cap of 2, want > 0", wait)
+	}
+}
+
+func TestLimiterReserveMinDelay(t *testing.T) {
+	store := storage.NewMemoryStore()
+	defer store.Close()
+
+	limiter := NewLimiter(store, slog.New(slog.DiscardHandler), map[string]ActionLimit{
+		"unfollow": {MinDelay: time.Hour},
+	})
+
+	ctx := context.Background()
+	if err := limiter.Record("unfollow", "user"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	wait, err := limiter.Reserve(ctx, "unfollow")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if wait <= 0 {
+		t.Fatalf("Reserve() wait = %v right after an action with a 1h MinDelay, want > 0", wait)
+	}
+}
+
+func TestLimiterReserveUnconfiguredActionIsUnthrottled(t *testing.T) {
+	store := storage.NewMemoryStore()
+	defer store.Close()
+
+	limiter := NewLimiter(store, slog.New(slog.DiscardHandler), map[string]ActionLimit{})
+
+	wait, err := limiter.Reserve(context.Background(), "like")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if wait != 0 {
+		t.Fatalf("Reserve() wait = %v for an unconfigured action, want 0", wait)
+	}
+}
@@ -0,0 +1,143 @@
+// Package report renders a snapshot of a Store's relationships as a
+// standalone HTML report plus per-category CSV exports, so a run can be
+// shared or diffed without a database connection.
+package report
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/worlddrknss/instagram-unfollow-v2/pkg/storage"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+// category is one CSV/table section of the report.
+type category struct {
+	Name          string
+	Slug          string
+	Relationships []storage.Relationship
+}
+
+type reportData struct {
+	GeneratedAt time.Time
+	Categories  []category
+}
+
+// Generate queries store for each report category and writes report.html
+// plus one CSV file per category into dir, creating it if needed.
+func Generate(store storage.Store, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create report dir: %w", err)
+	}
+
+	following, err := store.AllFollowing()
+	if err != nil {
+		return fmt.Errorf("load following: %w", err)
+	}
+	followers, err := store.AllFollowers()
+	if err != nil {
+		return fmt.Errorf("load followers: %w", err)
+	}
+	unfollowCandidates, err := store.UnfollowCandidates()
+	if err != nil {
+		return fmt.Errorf("load unfollow candidates: %w", err)
+	}
+	fans, err := store.FanCandidates()
+	if err != nil {
+		return fmt.Errorf("load fan candidates: %w", err)
+	}
+	mutuals, err := store.Mutuals()
+	if err != nil {
+		return fmt.Errorf("load mutuals: %w", err)
+	}
+
+	protected, err := store.ListProtected()
+	if err != nil {
+		return fmt.Errorf("load protected accounts: %w", err)
+	}
+	private := make(map[string]bool, len(protected))
+	for _, p := range protected {
+		if p.Access == storage.AccessPrivate {
+			private[p.Username] = true
+		}
+	}
+
+	data := reportData{
+		GeneratedAt: time.Now(),
+		Categories: []category{
+			{Name: "Following", Slug: "following", Relationships: redactPrivate(following, private)},
+			{Name: "Followers", Slug: "followers", Relationships: redactPrivate(followers, private)},
+			{Name: "Unfollow Candidates", Slug: "unfollow_candidates", Relationships: redactPrivate(unfollowCandidates, private)},
+			{Name: "Fans", Slug: "fans", Relationships: redactPrivate(fans, private)},
+			{Name: "Mutuals", Slug: "mutuals", Relationships: redactPrivate(mutuals, private)},
+		},
+	}
+
+	for _, c := range data.Categories {
+		if err := writeCSV(filepath.Join(dir, c.Slug+".csv"), c.Relationships); err != nil {
+			return fmt.Errorf("write %s csv: %w", c.Slug, err)
+		}
+	}
+
+	return writeHTML(filepath.Join(dir, "report.html"), data)
+}
+
+// redactPrivate returns a copy of rows with the username and href of any
+// PRIVATE-protected account replaced, so CSV and HTML exports never leak a
+// handle the user asked to keep out of shared reports.
+func redactPrivate(rows []storage.Relationship, private map[string]bool) []storage.Relationship {
+	if len(private) == 0 {
+		return rows
+	}
+	out := make([]storage.Relationship, len(rows))
+	for i, r := range rows {
+		if private[r.Username] {
+			r.Username = "[redacted]"
+			r.Href = ""
+		}
+		out[i] = r
+	}
+	return out
+}
+
+func writeCSV(path string, rows []storage.Relationship) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write([]string{"username", "href", "timestamp"}); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := w.Write([]string{r.Username, r.Href, fmt.Sprintf("%d", r.Timestamp)}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeHTML(path string, data reportData) error {
+	tmpl, err := template.New("report").Parse(reportTemplate)
+	if err != nil {
+		return fmt.Errorf("parse template: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tmpl.Execute(f, data)
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// MutualFollowers returns every account that's both a follower and a
+// following. It's a thin, descriptively-named wrapper over Store.Mutuals
+// for callers building graph analytics on top of the store.
+func MutualFollowers(store Store) ([]Relationship, error) {
+	return store.Mutuals()
+}
+
+// FansNotFollowedBack returns every account that follows you but that you
+// don't follow back. It's a thin, descriptively-named wrapper over
+// Store.FanCandidates for callers building graph analytics on top of the
+// store.
+func FansNotFollowedBack(store Store) ([]Relationship, error) {
+	return store.FanCandidates()
+}
+
+// ChurnSince returns who started and stopped following you between the
+// snapshot closest to (at or before) since and the most recent import.
+// It requires at least one snapshot to have been recorded at or before
+// since; otherwise it returns an error rather than silently diffing
+// against nothing.
+func ChurnSince(store Store, since time.Time) (gained, lost []Relationship, err error) {
+	baseline, err := store.SnapshotBefore(since)
+	if err != nil {
+		return nil, nil, fmt.Errorf("find baseline snapshot: %w", err)
+	}
+	if baseline == 0 {
+		return nil, nil, fmt.Errorf("no snapshot recorded at or before %s", since.Format(time.RFC3339))
+	}
+
+	gained, err = store.NewFollowers(baseline)
+	if err != nil {
+		return nil, nil, fmt.Errorf("new followers since snapshot %d: %w", baseline, err)
+	}
+	lost, err = store.NewUnfollowers(baseline)
+	if err != nil {
+		return nil, nil, fmt.Errorf("new unfollowers since snapshot %d: %w", baseline, err)
+	}
+	return gained, lost, nil
+}
@@ -0,0 +1,468 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryStore is a hermetic, in-process Store implementation intended for
+// unit tests that don't want to pay for a temp-file SQLite database.
+type memoryStore struct {
+	mu sync.Mutex
+
+	following     map[string]Relationship
+	followers     map[string]Relationship
+	unfollowed    map[string]int64
+	notFollowing  map[string]int64
+	relationships map[string]map[string]Relationship // kind -> username -> relationship
+	protected     map[string]ProtectedAccount
+
+	actions []memoryAction
+
+	snapshots         []Snapshot
+	nextSnapshotID    int64
+	snapshotRelations []memorySnapshotRelationship
+}
+
+type memoryAction struct {
+	actionType string
+	username   string
+	at         int64
+}
+
+type memorySnapshotRelationship struct {
+	snapshotID int64
+	kind       string
+	rel        Relationship
+	firstSeen  int64
+	lastSeen   int64
+}
+
+// NewMemoryStore returns a Store backed entirely by in-process maps.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		following:     make(map[string]Relationship),
+		followers:     make(map[string]Relationship),
+		unfollowed:    make(map[string]int64),
+		notFollowing:  make(map[string]int64),
+		relationships: make(map[string]map[string]Relationship),
+		protected:     make(map[string]ProtectedAccount),
+	}
+}
+
+func (m *memoryStore) Close() error { return nil }
+
+func (m *memoryStore) UpsertFollowing(rows []Relationship) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range rows {
+		m.following[r.Username] = r
+	}
+	return nil
+}
+
+func (m *memoryStore) UpsertFollowers(rows []Relationship) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range rows {
+		m.followers[r.Username] = r
+	}
+	return nil
+}
+
+func (m *memoryStore) AllFollowing() ([]Relationship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return sortedRelationships(m.following), nil
+}
+
+func (m *memoryStore) AllFollowers() ([]Relationship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return sortedRelationships(m.followers), nil
+}
+
+func sortedRelationships(rels map[string]Relationship) []Relationship {
+	out := make([]Relationship, 0, len(rels))
+	for _, r := range rels {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Username < out[j].Username })
+	return out
+}
+
+func (m *memoryStore) UnfollowCandidates() ([]Relationship, error) {
+	return m.UnfollowCandidatesFiltered(UnfollowFilterOptions{})
+}
+
+func (m *memoryStore) FanCandidates() ([]Relationship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out []Relationship
+	for username, r := range m.followers {
+		if _, ok := m.following[username]; ok {
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Timestamp > out[j].Timestamp })
+	return out, nil
+}
+
+func (m *memoryStore) Mutuals() ([]Relationship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out []Relationship
+	for username, r := range m.following {
+		if _, ok := m.followers[username]; ok {
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Username < out[j].Username })
+	return out, nil
+}
+
+func (m *memoryStore) UnfollowCandidatesFiltered(opts UnfollowFilterOptions) ([]Relationship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out []Relationship
+	for username, r := range m.following {
+		if _, ok := m.followers[username]; ok {
+			continue
+		}
+		if _, ok := m.unfollowed[username]; ok {
+			continue
+		}
+		if _, ok := m.notFollowing[username]; ok {
+			continue
+		}
+		if _, ok := m.protected[username]; ok {
+			continue
+		}
+		if opts.ExcludeCloseFriends && m.inRelationshipKind("close_friends", username) {
+			continue
+		}
+		if opts.ExcludeRestricted && m.inRelationshipKind("restricted_profiles", username) {
+			continue
+		}
+		if opts.ExcludeRecentlyUnfollowed && m.inRelationshipKind("recently_unfollowed", username) {
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Timestamp > out[j].Timestamp })
+	return out, nil
+}
+
+func (m *memoryStore) inRelationshipKind(kind, username string) bool {
+	_, ok := m.relationships[kind][username]
+	return ok
+}
+
+func (m *memoryStore) UpsertRelationships(kind string, rows []Relationship) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.relationships[kind] == nil {
+		m.relationships[kind] = make(map[string]Relationship)
+	}
+	for _, r := range rows {
+		m.relationships[kind][r.Username] = r
+	}
+	return nil
+}
+
+func (m *memoryStore) MutualCloseFriends() ([]Relationship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out []Relationship
+	for username, r := range m.relationships["close_friends"] {
+		if _, ok := m.following[username]; !ok {
+			continue
+		}
+		if _, ok := m.followers[username]; !ok {
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Username < out[j].Username })
+	return out, nil
+}
+
+func (m *memoryStore) PendingYouSent() ([]Relationship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out []Relationship
+	for _, r := range m.relationships["pending_follow_requests"] {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Username < out[j].Username })
+	return out, nil
+}
+
+func (m *memoryStore) Protect(username, reason string, access Access) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.protected[username] = ProtectedAccount{
+		Username: username,
+		Reason:   reason,
+		AddedAt:  time.Now().Unix(),
+		Access:   access,
+	}
+	return nil
+}
+
+func (m *memoryStore) Unprotect(username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.protected, username)
+	return nil
+}
+
+func (m *memoryStore) ListProtected() ([]ProtectedAccount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]ProtectedAccount, 0, len(m.protected))
+	for _, p := range m.protected {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Username < out[j].Username })
+	return out, nil
+}
+
+func (m *memoryStore) NewSnapshot(sourceExportPath string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextSnapshotID++
+	m.snapshots = append(m.snapshots, Snapshot{
+		ID:               m.nextSnapshotID,
+		CreatedAt:        time.Now().Unix(),
+		SourceExportPath: sourceExportPath,
+	})
+	return m.nextSnapshotID, nil
+}
+
+func (m *memoryStore) RecordSnapshotRelationships(snapshotID int64, kind string, rows []Relationship) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().Unix()
+	for _, r := range rows {
+		firstSeen := r.Timestamp
+		for _, sr := range m.snapshotRelations {
+			if sr.kind == kind && sr.rel.Username == r.Username && (firstSeen == 0 || sr.firstSeen < firstSeen) {
+				firstSeen = sr.firstSeen
+			}
+		}
+		m.snapshotRelations = append(m.snapshotRelations, memorySnapshotRelationship{
+			snapshotID: snapshotID,
+			kind:       kind,
+			rel:        r,
+			firstSeen:  firstSeen,
+			lastSeen:   now,
+		})
+	}
+	return nil
+}
+
+func (m *memoryStore) SnapshotBefore(when time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var id int64
+	cutoff := when.Unix()
+	for _, s := range m.snapshots {
+		if s.CreatedAt <= cutoff && s.ID > id {
+			id = s.ID
+		}
+	}
+	return id, nil
+}
+
+func (m *memoryStore) latestSnapshotID() int64 {
+	var latest int64
+	for _, s := range m.snapshots {
+		if s.ID > latest {
+			latest = s.ID
+		}
+	}
+	return latest
+}
+
+func (m *memoryStore) NewFollowers(sinceSnapshotID int64) ([]Relationship, error) {
+	return m.diffSnapshot("followers", sinceSnapshotID)
+}
+
+func (m *memoryStore) NewUnfollowers(sinceSnapshotID int64) ([]Relationship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	latest := m.latestSnapshotID()
+	stillFollower := make(map[string]bool)
+	for _, sr := range m.snapshotRelations {
+		if sr.kind == "followers" && sr.snapshotID == latest {
+			stillFollower[sr.rel.Username] = true
+		}
+	}
+
+	var out []Relationship
+	for _, sr := range m.snapshotRelations {
+		if sr.kind != "followers" || sr.snapshotID != sinceSnapshotID {
+			continue
+		}
+		if stillFollower[sr.rel.Username] {
+			continue
+		}
+		out = append(out, sr.rel)
+	}
+	return out, nil
+}
+
+func (m *memoryStore) diffSnapshot(kind string, sinceSnapshotID int64) ([]Relationship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	latest := m.latestSnapshotID()
+	before := make(map[string]bool)
+	for _, sr := range m.snapshotRelations {
+		if sr.kind == kind && sr.snapshotID == sinceSnapshotID {
+			before[sr.rel.Username] = true
+		}
+	}
+
+	var out []Relationship
+	for _, sr := range m.snapshotRelations {
+		if sr.kind != kind || sr.snapshotID != latest {
+			continue
+		}
+		if before[sr.rel.Username] {
+			continue
+		}
+		out = append(out, sr.rel)
+	}
+	return out, nil
+}
+
+func (m *memoryStore) MarkUnfollowed(username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unfollowed[username] = time.Now().Unix()
+	return nil
+}
+
+func (m *memoryStore) UnfollowedCount() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.unfollowed), nil
+}
+
+func (m *memoryStore) MarkNotFollowing(username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notFollowing[username] = time.Now().Unix()
+	return nil
+}
+
+func (m *memoryStore) RemoveFromFollowing(username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.following, username)
+	return nil
+}
+
+func (m *memoryStore) RecordAction(actionType, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.actions = append(m.actions, memoryAction{actionType: actionType, username: username, at: time.Now().Unix()})
+	return nil
+}
+
+func (m *memoryStore) ActionsInLastHour(actionType string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := time.Now().Add(-time.Hour).Unix()
+	count := 0
+	for _, a := range m.actions {
+		if a.actionType == actionType && a.at > cutoff {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryStore) OldestActionInLastHour(actionType string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := time.Now().Add(-time.Hour).Unix()
+	var oldest int64
+	for _, a := range m.actions {
+		if a.actionType == actionType && a.at > cutoff {
+			if oldest == 0 || a.at < oldest {
+				oldest = a.at
+			}
+		}
+	}
+	return oldest, nil
+}
+
+func (m *memoryStore) NewestActionInLastHour(actionType string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := time.Now().Add(-time.Hour).Unix()
+	var newest int64
+	for _, a := range m.actions {
+		if a.actionType == actionType && a.at > cutoff && a.at > newest {
+			newest = a.at
+		}
+	}
+	return newest, nil
+}
+
+func (m *memoryStore) ActionsInWindow(actionType string, window time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := time.Now().Add(-window).Unix()
+	count := 0
+	for _, a := range m.actions {
+		if a.actionType == actionType && a.at > cutoff {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryStore) OldestActionInWindow(actionType string, window time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := time.Now().Add(-window).Unix()
+	var oldest int64
+	for _, a := range m.actions {
+		if a.actionType == actionType && a.at > cutoff {
+			if oldest == 0 || a.at < oldest {
+				oldest = a.at
+			}
+		}
+	}
+	return oldest, nil
+}
+
+func (m *memoryStore) NewestActionInWindow(actionType string, window time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := time.Now().Add(-window).Unix()
+	var newest int64
+	for _, a := range m.actions {
+		if a.actionType == actionType && a.at > cutoff && a.at > newest {
+			newest = a.at
+		}
+	}
+	return newest, nil
+}
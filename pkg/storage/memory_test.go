@@ -0,0 +1,156 @@
+package storage
+
+import "testing"
+
+func TestMemoryStoreUnfollowCandidates(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if err := store.UpsertFollowing([]Relationship{
+		{Username: "alice", Timestamp: 2},
+		{Username: "bob", Timestamp: 1},
+	}); err != nil {
+		t.Fatalf("UpsertFollowing: %v", err)
+	}
+	if err := store.UpsertFollowers([]Relationship{{Username: "alice", Timestamp: 2}}); err != nil {
+		t.Fatalf("UpsertFollowers: %v", err)
+	}
+
+	candidates, err := store.UnfollowCandidates()
+	if err != nil {
+		t.Fatalf("UnfollowCandidates: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Username != "bob" {
+		t.Fatalf("expected only bob as a candidate, got %+v", candidates)
+	}
+
+	if err := store.MarkUnfollowed("bob"); err != nil {
+		t.Fatalf("MarkUnfollowed: %v", err)
+	}
+	if candidates, err = store.UnfollowCandidates(); err != nil {
+		t.Fatalf("UnfollowCandidates: %v", err)
+	} else if len(candidates) != 0 {
+		t.Fatalf("expected no candidates after unfollowing bob, got %+v", candidates)
+	}
+}
+
+func TestMemoryStoreProtectedAccounts(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if err := store.UpsertFollowing([]Relationship{
+		{Username: "alice", Timestamp: 2},
+		{Username: "bob", Timestamp: 1},
+	}); err != nil {
+		t.Fatalf("UpsertFollowing: %v", err)
+	}
+
+	if err := store.Protect("bob", "business partner", AccessPrivate); err != nil {
+		t.Fatalf("Protect: %v", err)
+	}
+
+	candidates, err := store.UnfollowCandidates()
+	if err != nil {
+		t.Fatalf("UnfollowCandidates: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Username != "alice" {
+		t.Fatalf("expected bob to be filtered out, got %+v", candidates)
+	}
+
+	protected, err := store.ListProtected()
+	if err != nil {
+		t.Fatalf("ListProtected: %v", err)
+	}
+	if len(protected) != 1 || protected[0].Username != "bob" || protected[0].Access != AccessPrivate {
+		t.Fatalf("expected bob protected with PRIVATE access, got %+v", protected)
+	}
+
+	if err := store.Unprotect("bob"); err != nil {
+		t.Fatalf("Unprotect: %v", err)
+	}
+	if candidates, err = store.UnfollowCandidates(); err != nil {
+		t.Fatalf("UnfollowCandidates: %v", err)
+	} else if len(candidates) != 2 {
+		t.Fatalf("expected both users as candidates after unprotecting bob, got %+v", candidates)
+	}
+}
+
+func TestMemoryStoreFanCandidatesAndMutuals(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if err := store.UpsertFollowing([]Relationship{
+		{Username: "alice"},
+		{Username: "bob"},
+	}); err != nil {
+		t.Fatalf("UpsertFollowing: %v", err)
+	}
+	if err := store.UpsertFollowers([]Relationship{
+		{Username: "alice"},
+		{Username: "carol"},
+	}); err != nil {
+		t.Fatalf("UpsertFollowers: %v", err)
+	}
+
+	fans, err := store.FanCandidates()
+	if err != nil {
+		t.Fatalf("FanCandidates: %v", err)
+	}
+	if len(fans) != 1 || fans[0].Username != "carol" {
+		t.Fatalf("expected only carol as a fan, got %+v", fans)
+	}
+
+	mutuals, err := store.Mutuals()
+	if err != nil {
+		t.Fatalf("Mutuals: %v", err)
+	}
+	if len(mutuals) != 1 || mutuals[0].Username != "alice" {
+		t.Fatalf("expected only alice as a mutual, got %+v", mutuals)
+	}
+}
+
+func TestMemoryStoreSnapshotDiff(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	first, err := store.NewSnapshot("export-1")
+	if err != nil {
+		t.Fatalf("NewSnapshot: %v", err)
+	}
+	if err := store.RecordSnapshotRelationships(first, "followers", []Relationship{
+		{Username: "alice", Timestamp: 1},
+		{Username: "bob", Timestamp: 1},
+	}); err != nil {
+		t.Fatalf("RecordSnapshotRelationships: %v", err)
+	}
+
+	if _, err := store.NewSnapshot("export-2"); err != nil {
+		t.Fatalf("NewSnapshot: %v", err)
+	}
+	second, err := store.NewSnapshot("export-3")
+	if err != nil {
+		t.Fatalf("NewSnapshot: %v", err)
+	}
+	if err := store.RecordSnapshotRelationships(second, "followers", []Relationship{
+		{Username: "alice", Timestamp: 2},
+		{Username: "carol", Timestamp: 2},
+	}); err != nil {
+		t.Fatalf("RecordSnapshotRelationships: %v", err)
+	}
+
+	newFollowers, err := store.NewFollowers(first)
+	if err != nil {
+		t.Fatalf("NewFollowers: %v", err)
+	}
+	if len(newFollowers) != 1 || newFollowers[0].Username != "carol" {
+		t.Fatalf("expected carol as the only new follower, got %+v", newFollowers)
+	}
+
+	newUnfollowers, err := store.NewUnfollowers(first)
+	if err != nil {
+		t.Fatalf("NewUnfollowers: %v", err)
+	}
+	if len(newUnfollowers) != 1 || newUnfollowers[0].Username != "bob" {
+		t.Fatalf("expected bob as the only new unfollower, got %+v", newUnfollowers)
+	}
+}
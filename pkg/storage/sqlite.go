@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) name() string { return "sqlite" }
+
+func (sqliteDialect) nowExpr() string { return "strftime('%s', 'now')" }
+
+func (sqliteDialect) rebind(query string) string { return query }
+
+func (sqliteDialect) upsert(table string, cols, vals, conflictCols []string) string {
+	return fmt.Sprintf(
+		"INSERT OR REPLACE INTO %s (%s) VALUES (%s)",
+		table, strings.Join(cols, ", "), strings.Join(vals, ", "),
+	)
+}
+
+func (sqliteDialect) schemaStatements() []string {
+	return []string{
+		`PRAGMA journal_mode = WAL;`,
+		`CREATE TABLE IF NOT EXISTS following (
+			username TEXT PRIMARY KEY,
+			href TEXT,
+			timestamp INTEGER
+		);`,
+		`CREATE TABLE IF NOT EXISTS followers (
+			username TEXT PRIMARY KEY,
+			href TEXT,
+			timestamp INTEGER
+		);`,
+		`CREATE TABLE IF NOT EXISTS unfollowed (
+			username TEXT PRIMARY KEY,
+			unfollowed_at INTEGER
+		);`,
+		`CREATE TABLE IF NOT EXISTS session_actions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			action_type TEXT NOT NULL,
+			username TEXT,
+			action_at INTEGER NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS not_following (
+			username TEXT PRIMARY KEY,
+			detected_at INTEGER
+		);`,
+		`CREATE TABLE IF NOT EXISTS snapshots (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			created_at INTEGER NOT NULL,
+			source_export_path TEXT
+		);`,
+		`CREATE TABLE IF NOT EXISTS snapshot_relationships (
+			snapshot_id INTEGER NOT NULL REFERENCES snapshots(id),
+			kind TEXT NOT NULL,
+			username TEXT NOT NULL,
+			href TEXT,
+			first_seen INTEGER,
+			last_seen INTEGER,
+			PRIMARY KEY (snapshot_id, kind, username)
+		);`,
+		`CREATE TABLE IF NOT EXISTS relationships (
+			kind TEXT NOT NULL,
+			username TEXT NOT NULL,
+			href TEXT,
+			timestamp INTEGER,
+			PRIMARY KEY (kind, username)
+		);`,
+		`CREATE TABLE IF NOT EXISTS protected_accounts (
+			username TEXT PRIMARY KEY,
+			reason TEXT,
+			added_at INTEGER,
+			access TEXT
+		);`,
+	}
+}
+
+// NewSQLiteStore opens (or creates) a SQLite database at dbPath and ensures
+// schema is present.
+func NewSQLiteStore(dbPath string) (Store, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite db: %w", err)
+	}
+
+	return newSQLStore(db, sqliteDialect{})
+}
@@ -0,0 +1,417 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// dialect isolates the handful of places SQLite and Postgres SQL diverges:
+// autoincrement syntax, upsert syntax, and the current-time expression.
+// Everything else is shared between the two backends.
+type dialect interface {
+	name() string
+	schemaStatements() []string
+	nowExpr() string
+	// upsert builds an insert-or-update statement. cols and vals are the
+	// column names and their corresponding value expressions (either "?"
+	// placeholders or literal SQL like nowExpr()); conflictCols identifies
+	// the columns the upsert should key on.
+	upsert(table string, cols, vals, conflictCols []string) string
+	rebind(query string) string
+}
+
+// sqlStore implements Store on top of database/sql, parameterized by dialect
+// so SQLite and Postgres can share all query logic.
+type sqlStore struct {
+	db *sql.DB
+	d  dialect
+}
+
+func newSQLStore(db *sql.DB, d dialect) (*sqlStore, error) {
+	for _, stmt := range d.schemaStatements() {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("schema setup: %w", err)
+		}
+	}
+	return &sqlStore{db: db, d: d}, nil
+}
+
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *sqlStore) UpsertFollowing(rows []Relationship) error {
+	return s.upsertRelationships("following", rows)
+}
+
+func (s *sqlStore) UpsertFollowers(rows []Relationship) error {
+	return s.upsertRelationships("followers", rows)
+}
+
+func (s *sqlStore) upsertRelationships(table string, rows []Relationship) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	cols := []string{"username", "href", "timestamp"}
+	query := s.d.rebind(s.d.upsert(table, cols, []string{"?", "?", "?"}, []string{"username"}))
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return fmt.Errorf("prepare: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		if _, err := stmt.Exec(r.Username, r.Href, r.Timestamp); err != nil {
+			return fmt.Errorf("exec %s: %w", table, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *sqlStore) AllFollowing() ([]Relationship, error) {
+	return s.queryRelationships("SELECT username, href, timestamp FROM following ORDER BY username;")
+}
+
+func (s *sqlStore) AllFollowers() ([]Relationship, error) {
+	return s.queryRelationships("SELECT username, href, timestamp FROM followers ORDER BY username;")
+}
+
+func (s *sqlStore) UnfollowCandidates() ([]Relationship, error) {
+	return s.UnfollowCandidatesFiltered(UnfollowFilterOptions{})
+}
+
+func (s *sqlStore) FanCandidates() ([]Relationship, error) {
+	query := `
+		SELECT fr.username, fr.href, fr.timestamp
+		FROM followers fr
+		LEFT JOIN following f ON fr.username = f.username
+		WHERE f.username IS NULL
+		ORDER BY fr.timestamp DESC;
+	`
+	return s.queryRelationships(query)
+}
+
+func (s *sqlStore) Mutuals() ([]Relationship, error) {
+	query := `
+		SELECT f.username, f.href, f.timestamp
+		FROM following f
+		JOIN followers fr ON f.username = fr.username
+		ORDER BY f.username;
+	`
+	return s.queryRelationships(query)
+}
+
+func (s *sqlStore) UnfollowCandidatesFiltered(opts UnfollowFilterOptions) ([]Relationship, error) {
+	query := `
+		SELECT f.username, f.href, f.timestamp
+		FROM following f
+		LEFT JOIN followers fr ON f.username = fr.username
+		LEFT JOIN unfollowed u ON f.username = u.username
+		LEFT JOIN not_following nf ON f.username = nf.username
+		LEFT JOIN protected_accounts pa ON f.username = pa.username
+		WHERE fr.username IS NULL AND u.username IS NULL AND nf.username IS NULL AND pa.username IS NULL
+	`
+	if opts.ExcludeCloseFriends {
+		query += " AND f.username NOT IN (SELECT username FROM relationships WHERE kind = 'close_friends')"
+	}
+	if opts.ExcludeRestricted {
+		query += " AND f.username NOT IN (SELECT username FROM relationships WHERE kind = 'restricted_profiles')"
+	}
+	if opts.ExcludeRecentlyUnfollowed {
+		query += " AND f.username NOT IN (SELECT username FROM relationships WHERE kind = 'recently_unfollowed')"
+	}
+	query += " ORDER BY f.timestamp DESC;"
+
+	return s.queryRelationships(query)
+}
+
+func (s *sqlStore) UpsertRelationships(kind string, rows []Relationship) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	cols := []string{"kind", "username", "href", "timestamp"}
+	query := s.d.rebind(s.d.upsert("relationships", cols, []string{"?", "?", "?", "?"}, []string{"kind", "username"}))
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return fmt.Errorf("prepare: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		if _, err := stmt.Exec(kind, r.Username, r.Href, r.Timestamp); err != nil {
+			return fmt.Errorf("exec relationships: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *sqlStore) MutualCloseFriends() ([]Relationship, error) {
+	query := `
+		SELECT cf.username, cf.href, cf.timestamp
+		FROM relationships cf
+		JOIN following fg ON fg.username = cf.username
+		JOIN followers fr ON fr.username = cf.username
+		WHERE cf.kind = 'close_friends'
+		ORDER BY cf.username;
+	`
+	return s.queryRelationships(query)
+}
+
+func (s *sqlStore) PendingYouSent() ([]Relationship, error) {
+	query := s.d.rebind("SELECT username, href, timestamp FROM relationships WHERE kind = ? ORDER BY username;")
+	return s.queryRelationships(query, "pending_follow_requests")
+}
+
+func (s *sqlStore) NewSnapshot(sourceExportPath string) (int64, error) {
+	query := s.d.rebind(fmt.Sprintf(
+		"INSERT INTO snapshots (created_at, source_export_path) VALUES (%s, ?)", s.d.nowExpr(),
+	))
+
+	if s.d.name() == "postgres" {
+		var id int64
+		if err := s.db.QueryRow(query+" RETURNING id", sourceExportPath).Scan(&id); err != nil {
+			return 0, fmt.Errorf("insert snapshot: %w", err)
+		}
+		return id, nil
+	}
+
+	res, err := s.db.Exec(query, sourceExportPath)
+	if err != nil {
+		return 0, fmt.Errorf("insert snapshot: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+func (s *sqlStore) RecordSnapshotRelationships(snapshotID int64, kind string, rows []Relationship) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	firstSeenStmt, err := tx.Prepare(s.d.rebind(
+		"SELECT MIN(first_seen) FROM snapshot_relationships WHERE kind = ? AND username = ?",
+	))
+	if err != nil {
+		return fmt.Errorf("prepare first_seen lookup: %w", err)
+	}
+	defer firstSeenStmt.Close()
+
+	cols := []string{"snapshot_id", "kind", "username", "href", "first_seen", "last_seen"}
+	vals := []string{"?", "?", "?", "?", "?", s.d.nowExpr()}
+	insertStmt, err := tx.Prepare(s.d.rebind(s.d.upsert("snapshot_relationships", cols, vals, []string{"snapshot_id", "kind", "username"})))
+	if err != nil {
+		return fmt.Errorf("prepare insert: %w", err)
+	}
+	defer insertStmt.Close()
+
+	for _, r := range rows {
+		var firstSeen sql.NullInt64
+		if err := firstSeenStmt.QueryRow(kind, r.Username).Scan(&firstSeen); err != nil {
+			return fmt.Errorf("lookup first_seen for %s: %w", r.Username, err)
+		}
+
+		seen := r.Timestamp
+		if firstSeen.Valid && firstSeen.Int64 > 0 {
+			seen = firstSeen.Int64
+		}
+
+		if _, err := insertStmt.Exec(snapshotID, kind, r.Username, r.Href, seen); err != nil {
+			return fmt.Errorf("exec snapshot_relationships: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *sqlStore) NewFollowers(sinceSnapshotID int64) ([]Relationship, error) {
+	return s.diffSnapshotRelationships("followers", sinceSnapshotID)
+}
+
+func (s *sqlStore) NewUnfollowers(sinceSnapshotID int64) ([]Relationship, error) {
+	query := s.d.rebind(`
+		SELECT old.username, old.href, old.last_seen
+		FROM snapshot_relationships old
+		WHERE old.snapshot_id = ? AND old.kind = 'followers'
+		AND old.username NOT IN (
+			SELECT username FROM snapshot_relationships
+			WHERE kind = 'followers' AND snapshot_id = (SELECT MAX(id) FROM snapshots)
+		)
+		ORDER BY old.last_seen DESC;
+	`)
+
+	return s.queryRelationships(query, sinceSnapshotID)
+}
+
+func (s *sqlStore) SnapshotBefore(when time.Time) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(
+		s.d.rebind("SELECT COALESCE(MAX(id), 0) FROM snapshots WHERE created_at <= ?"),
+		when.Unix(),
+	).Scan(&id)
+	return id, err
+}
+
+func (s *sqlStore) diffSnapshotRelationships(kind string, sinceSnapshotID int64) ([]Relationship, error) {
+	query := s.d.rebind(`
+		SELECT cur.username, cur.href, cur.last_seen
+		FROM snapshot_relationships cur
+		WHERE cur.snapshot_id = (SELECT MAX(id) FROM snapshots) AND cur.kind = ?
+		AND cur.username NOT IN (
+			SELECT username FROM snapshot_relationships WHERE kind = ? AND snapshot_id = ?
+		)
+		ORDER BY cur.last_seen DESC;
+	`)
+
+	return s.queryRelationships(query, kind, kind, sinceSnapshotID)
+}
+
+func (s *sqlStore) queryRelationships(query string, args ...interface{}) ([]Relationship, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query relationships: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Relationship
+	for rows.Next() {
+		var r Relationship
+		if err := rows.Scan(&r.Username, &r.Href, &r.Timestamp); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		out = append(out, r)
+	}
+
+	return out, rows.Err()
+}
+
+func (s *sqlStore) MarkUnfollowed(username string) error {
+	query := s.d.rebind(s.d.upsert("unfollowed", []string{"username", "unfollowed_at"}, []string{"?", s.d.nowExpr()}, []string{"username"}))
+	if _, err := s.db.Exec(query, username); err != nil {
+		return fmt.Errorf("mark unfollowed: %w", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) UnfollowedCount() (int, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM unfollowed").Scan(&count)
+	return count, err
+}
+
+func (s *sqlStore) RecordAction(actionType, username string) error {
+	query := s.d.rebind(fmt.Sprintf(
+		"INSERT INTO session_actions (action_type, username, action_at) VALUES (?, ?, %s)", s.d.nowExpr(),
+	))
+	_, err := s.db.Exec(query, actionType, username)
+	return err
+}
+
+func (s *sqlStore) ActionsInLastHour(actionType string) (int, error) {
+	var count int
+	err := s.db.QueryRow(
+		s.d.rebind(fmt.Sprintf("SELECT COUNT(*) FROM session_actions WHERE action_type = ? AND action_at > (%s - 3600)", s.d.nowExpr())),
+		actionType,
+	).Scan(&count)
+	return count, err
+}
+
+func (s *sqlStore) OldestActionInLastHour(actionType string) (int64, error) {
+	var oldest int64
+	err := s.db.QueryRow(
+		s.d.rebind(fmt.Sprintf("SELECT COALESCE(MIN(action_at), 0) FROM session_actions WHERE action_type = ? AND action_at > (%s - 3600)", s.d.nowExpr())),
+		actionType,
+	).Scan(&oldest)
+	return oldest, err
+}
+
+func (s *sqlStore) NewestActionInLastHour(actionType string) (int64, error) {
+	var newest int64
+	err := s.db.QueryRow(
+		s.d.rebind(fmt.Sprintf("SELECT COALESCE(MAX(action_at), 0) FROM session_actions WHERE action_type = ? AND action_at > (%s - 3600)", s.d.nowExpr())),
+		actionType,
+	).Scan(&newest)
+	return newest, err
+}
+
+func (s *sqlStore) ActionsInWindow(actionType string, window time.Duration) (int, error) {
+	var count int
+	err := s.db.QueryRow(
+		s.d.rebind(fmt.Sprintf("SELECT COUNT(*) FROM session_actions WHERE action_type = ? AND action_at > (%s - ?)", s.d.nowExpr())),
+		actionType, int64(window.Seconds()),
+	).Scan(&count)
+	return count, err
+}
+
+func (s *sqlStore) OldestActionInWindow(actionType string, window time.Duration) (int64, error) {
+	var oldest int64
+	err := s.db.QueryRow(
+		s.d.rebind(fmt.Sprintf("SELECT COALESCE(MIN(action_at), 0) FROM session_actions WHERE action_type = ? AND action_at > (%s - ?)", s.d.nowExpr())),
+		actionType, int64(window.Seconds()),
+	).Scan(&oldest)
+	return oldest, err
+}
+
+func (s *sqlStore) NewestActionInWindow(actionType string, window time.Duration) (int64, error) {
+	var newest int64
+	err := s.db.QueryRow(
+		s.d.rebind(fmt.Sprintf("SELECT COALESCE(MAX(action_at), 0) FROM session_actions WHERE action_type = ? AND action_at > (%s - ?)", s.d.nowExpr())),
+		actionType, int64(window.Seconds()),
+	).Scan(&newest)
+	return newest, err
+}
+
+func (s *sqlStore) MarkNotFollowing(username string) error {
+	query := s.d.rebind(s.d.upsert("not_following", []string{"username", "detected_at"}, []string{"?", s.d.nowExpr()}, []string{"username"}))
+	_, err := s.db.Exec(query, username)
+	return err
+}
+
+func (s *sqlStore) RemoveFromFollowing(username string) error {
+	_, err := s.db.Exec(s.d.rebind("DELETE FROM following WHERE username = ?"), username)
+	return err
+}
+
+func (s *sqlStore) Protect(username, reason string, access Access) error {
+	cols := []string{"username", "reason", "added_at", "access"}
+	vals := []string{"?", "?", s.d.nowExpr(), "?"}
+	query := s.d.rebind(s.d.upsert("protected_accounts", cols, vals, []string{"username"}))
+	_, err := s.db.Exec(query, username, reason, string(access))
+	return err
+}
+
+func (s *sqlStore) Unprotect(username string) error {
+	_, err := s.db.Exec(s.d.rebind("DELETE FROM protected_accounts WHERE username = ?"), username)
+	return err
+}
+
+func (s *sqlStore) ListProtected() ([]ProtectedAccount, error) {
+	rows, err := s.db.Query("SELECT username, reason, added_at, access FROM protected_accounts ORDER BY username;")
+	if err != nil {
+		return nil, fmt.Errorf("query protected accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var out []ProtectedAccount
+	for rows.Next() {
+		var p ProtectedAccount
+		var access string
+		if err := rows.Scan(&p.Username, &p.Reason, &p.AddedAt, &access); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		p.Access = Access(access)
+		out = append(out, p)
+	}
+
+	return out, rows.Err()
+}